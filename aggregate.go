@@ -1,8 +1,10 @@
 package chanute
 
 import (
+	"context"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -12,15 +14,46 @@ import (
 type Environment struct {
 	Name    string
 	Session *session.Session
+	// Clients are used instead of Session when set
+	Clients *Clients
+}
+
+func (e *Environment) clients() *Clients {
+	if e.Clients != nil {
+		return e.Clients
+	}
+	return NewClients(e.Session)
 }
 
 type AggregateReport struct {
-	Config       *Config
+	Config *Config
+	// Environments are the result of each environment, in the order they were given
+	Environments []*EnvironmentResult
+	// Reports are the reports of every environment that didn't fail, in the same order
 	Reports      []*Report
 	LimitReports map[string]*LimitReport
 	CostReports  map[string]*CostReport
 }
 
+type EnvironmentStatus string
+
+const (
+	EnvironmentSucceeded EnvironmentStatus = "success"
+	// EnvironmentPartial has a report, but some checks or tags couldn't be looked up
+	EnvironmentPartial EnvironmentStatus = "partial"
+	// EnvironmentFailed has no report, because Trusted Advisor couldn't be reached or ctx was cancelled first
+	EnvironmentFailed EnvironmentStatus = "failed"
+)
+
+// EnvironmentResult is how generating one environment's report went
+type EnvironmentResult struct {
+	Name   string
+	Status EnvironmentStatus
+	// Err is why the report failed, or what is missing from a partial report
+	Err    error
+	Report *Report
+}
+
 type AggregateSummary struct {
 	allRows        []*AggregateRow
 	aggregatedRows []*AggregateRow
@@ -39,12 +72,62 @@ func (s *AggregateSummary) SummaryRows() [][]string {
 	return o
 }
 
+// SummaryCSV is the summary rows as CSV, with a header row
+func (s *AggregateSummary) SummaryCSV() string {
+	return CSVString(s.SummaryHeaders(), s.SummaryRows())
+}
+
+// AsciiReport is the summary table, followed by the total
+func (s *AggregateSummary) AsciiReport() string {
+	o := &strings.Builder{}
+	Table(o, s.SummaryHeaders(), s.SummaryRows())
+	o.WriteString("Total Monthly Savings: " + PrintDollars(s.TotalSavings) + "\n")
+	return o.String()
+}
+
+// Details are every resource behind each key, in the order of SummaryRows
 func (s *AggregateSummary) Details() []*AggregateDetail {
-	return nil
+	var o []*AggregateDetail
+	for _, kr := range s.aggregatedRows {
+		d := &AggregateDetail{Key: kr.Key, MonthlySavings: kr.MonthlySavings}
+		d.Resources = append(d.Resources, s.resourcesByKey[kr.Key]...)
+		sort.SliceStable(d.Resources, func(i, j int) bool {
+			a, b := d.Resources[i], d.Resources[j]
+			if a.MonthlySavings != b.MonthlySavings {
+				return a.MonthlySavings > b.MonthlySavings
+			}
+			if a.Service != b.Service {
+				return a.Service < b.Service
+			}
+			if a.Env != b.Env {
+				return a.Env < b.Env
+			}
+			if a.Region != b.Region {
+				return a.Region < b.Region
+			}
+			return a.ID < b.ID
+		})
+		o = append(o, d)
+	}
+	return o
+}
+
+// DetailsCSV is every detail in one CSV, with the key in the first column
+func (s *AggregateSummary) DetailsCSV() string {
+	var rows [][]string
+	for _, d := range s.Details() {
+		for _, row := range d.Rows() {
+			rows = append(rows, append([]string{d.Key}, row...))
+		}
+	}
+	return CSVString(append([]string{"Key"}, aggregateDetailHeaders...), rows)
 }
 
+// AggregateRow is one resource's savings. Env is the environment's name and is only set in an AggregateSummary.
+// ID is the resource's ID or name, and is empty for recommendations that aren't a resource.
 type AggregateRow struct {
 	Service, Key, Env string
+	Region, ID, Name  string
 	MonthlySavings    int
 }
 
@@ -53,19 +136,57 @@ type CostReporter interface {
 	Detail() *AggregateDetail
 }
 
+// AggregateDetail is the resources behind one key of an AggregateSummary, by savings
 type AggregateDetail struct {
-	Key string
+	Key            string
+	MonthlySavings int
+	Resources      []*AggregateRow
 }
 
+var aggregateDetailHeaders = []string{"Service", "Env", "Region", "ID", "Name", "Monthly Savings"}
+
+func (d *AggregateDetail) Headers() []string {
+	return aggregateDetailHeaders
+}
+
+func (d *AggregateDetail) Rows() [][]string {
+	var o [][]string
+	for _, r := range d.Resources {
+		o = append(o, []string{r.Service, r.Env, r.Region, r.ID, r.Name, strconv.Itoa(r.MonthlySavings)})
+	}
+	return o
+}
+
+// CSV is the resources as CSV, with a header row
 func (d *AggregateDetail) CSV() string {
-	return "TODO"
+	return CSVString(d.Headers(), d.Rows())
+}
+
+func (d *AggregateDetail) AsciiReport() string {
+	o := &strings.Builder{}
+	key := d.Key
+	if key == "" {
+		key = "(none)"
+	}
+	o.WriteString(key + ": " + PrintDollars(d.MonthlySavings) + "\n")
+	Table(o, d.Headers(), d.Rows())
+	return o.String()
 }
 
 func (r *AggregateReport) AggregatedCostSummary(a Aggregator) *AggregateSummary {
 	sum := &AggregateSummary{}
 
-	for _, report := range r.CostReports {
-		sum.allRows = append(sum.allRows, report.AggregateRows(a)...)
+	accounts := map[string]string{}
+	for _, e := range r.Environments {
+		if e.Report != nil {
+			accounts[e.Name] = e.Report.Account
+		}
+	}
+	for _, env := range r.costEnvironments() {
+		for _, row := range r.CostReports[env].AggregateRows(withAccount(a, accounts[env])) {
+			row.Env = env
+			sum.allRows = append(sum.allRows, row)
+		}
 	}
 
 	aggTotal := map[string]*AggregateRow{}
@@ -82,12 +203,36 @@ func (r *AggregateReport) AggregatedCostSummary(a Aggregator) *AggregateSummary
 	}
 
 	sort.Slice(sum.aggregatedRows, func(i, j int) bool {
-		return sum.aggregatedRows[i].MonthlySavings > sum.aggregatedRows[j].MonthlySavings
+		if sum.aggregatedRows[i].MonthlySavings != sum.aggregatedRows[j].MonthlySavings {
+			return sum.aggregatedRows[i].MonthlySavings > sum.aggregatedRows[j].MonthlySavings
+		}
+		return sum.aggregatedRows[i].Key < sum.aggregatedRows[j].Key
 	})
 
 	return sum
 }
 
+// costEnvironments are the names of CostReports, in the order of Environments
+func (r *AggregateReport) costEnvironments() []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, e := range r.Environments {
+		if _, ok := r.CostReports[e.Name]; ok && !seen[e.Name] {
+			names = append(names, e.Name)
+			seen[e.Name] = true
+		}
+	}
+	// reports that were added by hand aren't in Environments
+	var rest []string
+	for name := range r.CostReports {
+		if !seen[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	return append(names, rest...)
+}
+
 type AggregateByKey struct {
 	Key       string
 	Total     int
@@ -95,9 +240,15 @@ type AggregateByKey struct {
 }
 
 func GenerateAggregateReport(envs []*Environment, options ...Option) (*AggregateReport, error) {
-	var wg sync.WaitGroup
-	wg.Add(len(envs))
+	return GenerateAggregateReportWithContext(context.Background(), envs, options...)
+}
 
+// GenerateAggregateReportWithContext is GenerateAggregateReport, but every AWS call is made with ctx.
+// Environments that haven't started when ctx is cancelled fail.
+//
+// The report is returned even when environments fail or are partial, so an outage in one account doesn't lose the others.
+// Their errors are combined in the returned error, and each one's status is in Environments.
+func GenerateAggregateReportWithContext(ctx context.Context, envs []*Environment, options ...Option) (*AggregateReport, error) {
 	ar := &AggregateReport{
 		LimitReports: map[string]*LimitReport{},
 		CostReports:  map[string]*CostReport{},
@@ -108,25 +259,61 @@ func GenerateAggregateReport(envs []*Environment, options ...Option) (*Aggregate
 		return nil, errs.New("Aggregator is required")
 	}
 
-	var oErr error
-	for _, e := range envs {
-		go func(e *Environment) {
-			r, err := generateReport(e.Session, ar.Config)
-			if err != nil {
-				oErr = errs.Append(oErr, err)
-			} else {
-				ar.Reports = append(ar.Reports, r)
-				if r.ServiceLimits != nil {
-					ar.LimitReports[e.Name] = r.ServiceLimits
-				}
-				if r.CostOptimization != nil {
-					ar.CostReports[e.Name] = r.CostOptimization
-				}
-			}
-			wg.Done()
-		}(e)
+	concurrency := ar.Config.EnvironmentConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// each goroutine only writes its own result, so they're collected in order without a lock
+	ar.Environments = make([]*EnvironmentResult, len(envs))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, e := range envs {
+		wg.Add(1)
+		go func(i int, e *Environment) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			ar.Environments[i] = generateEnvironment(ctx, e, ar.Config)
+		}(i, e)
 	}
 	wg.Wait()
 
+	var oErr error
+	for _, res := range ar.Environments {
+		if res.Err != nil {
+			oErr = errs.Append(oErr, errs.NewWithCause(res.Name+" "+string(res.Status), res.Err))
+		}
+		if res.Report == nil {
+			continue
+		}
+		ar.Reports = append(ar.Reports, res.Report)
+		if res.Report.ServiceLimits != nil {
+			ar.LimitReports[res.Name] = res.Report.ServiceLimits
+		}
+		if res.Report.CostOptimization != nil {
+			ar.CostReports[res.Name] = res.Report.CostOptimization
+		}
+	}
+
 	return ar, oErr
 }
+
+func generateEnvironment(ctx context.Context, e *Environment, cfg *Config) *EnvironmentResult {
+	res := &EnvironmentResult{Name: e.Name}
+	if err := ctx.Err(); err != nil {
+		res.Status, res.Err = EnvironmentFailed, errs.NewWithCause("skipped", err)
+		return res
+	}
+
+	res.Report, res.Err = generateReport(ctx, e.clients(), cfg)
+	switch {
+	case res.Report == nil:
+		res.Status = EnvironmentFailed
+	case res.Err != nil:
+		res.Status = EnvironmentPartial
+	default:
+		res.Status = EnvironmentSucceeded
+	}
+	return res
+}
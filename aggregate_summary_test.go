@@ -0,0 +1,113 @@
+package chanute_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sheeley/chanute"
+)
+
+// goldenAggregateReport has keys and names that need quoting in CSV, an untagged resource, and ties on savings
+func goldenAggregateReport() *chanute.AggregateReport {
+	return &chanute.AggregateReport{
+		Environments: []*chanute.EnvironmentResult{{Name: "prod"}, {Name: "staging"}},
+		CostReports: map[string]*chanute.CostReport{
+			"staging": {
+				RDS: &chanute.RDSReport{Instances: []*chanute.RDSInstance{
+					{Region: "us-east-1", Name: "orders", EstimatedMonthlySavings: 40, Tags: map[string]string{"team": "Data, Analytics"}},
+				}},
+			},
+			"prod": {
+				EBS: &chanute.EBSReport{Volumes: []*chanute.EBSVolume{
+					{Region: "us-west-2b", ID: "vol-2", Name: "scratch\nspace", MonthlyStorageCost: 40, Tags: map[string]string{"team": "Data, Analytics"}},
+					{Region: "us-east-1a", ID: "vol-1", Name: `web "blue"`, MonthlyStorageCost: 15, Tags: map[string]string{"team": `web "blue"`}},
+					{Region: "us-east-1a", ID: "vol-3", MonthlyStorageCost: 5},
+				}},
+				RDS: &chanute.RDSReport{Instances: []*chanute.RDSInstance{
+					{Region: "us-east-1", Name: "orders", EstimatedMonthlySavings: 40, Tags: map[string]string{"team": "Data, Analytics"}},
+					{Region: "us-west-2", Name: "sessions", EstimatedMonthlySavings: 15, Tags: map[string]string{"team": `web "blue"`}},
+				}},
+			},
+		},
+	}
+}
+
+func TestAggregateSummaryOutput(t *testing.T) {
+	s := goldenAggregateReport().AggregatedCostSummary(chanute.NormalizedTag("team"))
+
+	tests := []struct {
+		name, got, want string
+	}{
+		{"summary CSV", s.SummaryCSV(), `Key,Monthly Savings
+"data, analytics",120
+"web ""blue""",30
+,5
+`},
+		// ties on savings are ordered by service, env, region and ID
+		{"details CSV", s.DetailsCSV(), `Key,Service,Env,Region,ID,Name,Monthly Savings
+"data, analytics",EBS,prod,us-west-2,vol-2,"scratch
+space",40
+"data, analytics",RDS,prod,us-east-1,orders,orders,40
+"data, analytics",RDS,staging,us-east-1,orders,orders,40
+"web ""blue""",EBS,prod,us-east-1,vol-1,"web ""blue""",15
+"web ""blue""",RDS,prod,us-west-2,sessions,sessions,15
+,EBS,prod,us-east-1,vol-3,,5
+`},
+		{"ascii report", s.AsciiReport(), `+-----------------+-----------------+
+|       KEY       | MONTHLY SAVINGS |
++-----------------+-----------------+
+| data, analytics |             120 |
+| web "blue"      |              30 |
+|                 |               5 |
++-----------------+-----------------+
+Total Monthly Savings: $155
+`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.got != test.want {
+				t.Errorf("got\n%s\nexpected\n%s", test.got, test.want)
+			}
+		})
+	}
+}
+
+func TestAggregateSummaryDetails(t *testing.T) {
+	details := goldenAggregateReport().AggregatedCostSummary(chanute.NormalizedTag("team")).Details()
+	if len(details) != 3 {
+		t.Fatalf("expected a detail per key, got %d", len(details))
+	}
+
+	want := []struct {
+		key     string
+		savings int
+		csv     string
+	}{
+		{"data, analytics", 120, `Service,Env,Region,ID,Name,Monthly Savings
+EBS,prod,us-west-2,vol-2,"scratch
+space",40
+RDS,prod,us-east-1,orders,orders,40
+RDS,staging,us-east-1,orders,orders,40
+`},
+		{`web "blue"`, 30, `Service,Env,Region,ID,Name,Monthly Savings
+EBS,prod,us-east-1,vol-1,"web ""blue""",15
+RDS,prod,us-west-2,sessions,sessions,15
+`},
+		{"", 5, `Service,Env,Region,ID,Name,Monthly Savings
+EBS,prod,us-east-1,vol-3,,5
+`},
+	}
+	for i, d := range details {
+		if d.Key != want[i].key || d.MonthlySavings != want[i].savings {
+			t.Errorf("detail %d is %q $%d, expected %q $%d", i, d.Key, d.MonthlySavings, want[i].key, want[i].savings)
+		}
+		if got := d.CSV(); got != want[i].csv {
+			t.Errorf("%q: got CSV\n%s\nexpected\n%s", d.Key, got, want[i].csv)
+		}
+	}
+
+	// resources without a key are still reported
+	if got := details[2].AsciiReport(); !strings.HasPrefix(got, "(none): $5\n") {
+		t.Errorf("expected the empty key to be reported as (none), got\n%s", got)
+	}
+}
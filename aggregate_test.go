@@ -0,0 +1,101 @@
+package chanute_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+// concurrency records how many environments are being generated at once
+type concurrency struct {
+	mu          sync.Mutex
+	active, max int
+}
+
+// countingSupport counts the environments describing checks at once, and fails to describe them if down is set
+type countingSupport struct {
+	*chanutetest.Support
+	count *concurrency
+	down  bool
+}
+
+func (s *countingSupport) DescribeTrustedAdvisorChecksWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorChecksInput, opts ...request.Option) (*support.DescribeTrustedAdvisorChecksOutput, error) {
+	s.count.mu.Lock()
+	s.count.active++
+	if s.count.active > s.count.max {
+		s.count.max = s.count.active
+	}
+	s.count.mu.Unlock()
+	defer func() {
+		s.count.mu.Lock()
+		s.count.active--
+		s.count.mu.Unlock()
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	if s.down {
+		return nil, awserr.New("SubscriptionRequiredException", "AWS Premium Support Subscription is required", nil)
+	}
+	return s.Support.DescribeTrustedAdvisorChecksWithContext(ctx, in, opts...)
+}
+
+func TestGenerateAggregateReportStatuses(t *testing.T) {
+	count := &concurrency{}
+	statuses := []chanute.EnvironmentStatus{chanute.EnvironmentSucceeded, chanute.EnvironmentFailed, chanute.EnvironmentPartial}
+	var envs []*chanute.Environment
+	// each status twice, so more environments are ready than can run at once
+	for i := 0; i < 6; i++ {
+		status := statuses[i%len(statuses)]
+		f := chanutetest.New()
+		f.Support.AddCheck("Ti39halfu8", string(chanute.CheckAmazonRDSIdleDBInstances), "cost_optimizing", rdsIdleColumns,
+			[]string{"us-east-1", "orders", "No", "db.m5.large", "100", "14+", "$120"},
+		)
+		f.RDS.AddInstance("arn:aws:rds:us-east-1:123456789012:db:orders", map[string]string{"team": "data"})
+
+		c := f.Clients()
+		c.Support = &countingSupport{Support: f.Support, count: count, down: status == chanute.EnvironmentFailed}
+		if status == chanute.EnvironmentPartial {
+			c.RDS = &throttledRDS{RDS: f.RDS}
+		}
+		envs = append(envs, &chanute.Environment{Name: fmt.Sprintf("%d-%s", i, status), Clients: c})
+	}
+
+	r, err := chanute.GenerateAggregateReport(envs,
+		chanute.WithChecks(chanute.CheckAmazonRDSIdleDBInstances),
+		chanute.WithAggregationByTag("team"),
+		chanute.WithEnvironmentConcurrency(2))
+	if err == nil {
+		t.Error("expected the failed and partial environments' errors")
+	}
+	if r == nil || len(r.Environments) != len(envs) {
+		t.Fatalf("expected a result per environment, got %+v", r)
+	}
+	for i, res := range r.Environments {
+		want := statuses[i%len(statuses)]
+		if res.Name != envs[i].Name || res.Status != want {
+			t.Errorf("result %d is %s %s, expected %s %s", i, res.Name, res.Status, envs[i].Name, want)
+		}
+		if (res.Report == nil) != (want == chanute.EnvironmentFailed) || (res.Err == nil) != (want == chanute.EnvironmentSucceeded) {
+			t.Errorf("%s: unexpected report %v or error %v", res.Name, res.Report, res.Err)
+		}
+	}
+
+	var reported []string
+	for name := range r.CostReports {
+		reported = append(reported, name)
+	}
+	if len(r.Reports) != 4 || len(r.CostReports) != 4 {
+		t.Errorf("expected 4 reports from the environments that didn't fail, got %d and cost reports of %v", len(r.Reports), reported)
+	}
+	if count.max > 2 {
+		t.Errorf("expected at most 2 environments at once, got %d", count.max)
+	}
+}
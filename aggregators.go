@@ -0,0 +1,139 @@
+package chanute
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The aggregators below can be combined into ownership rules, such as
+//
+//	FirstOf(NormalizedTag("team"), ParsedKV("organization", ":::", "=", "team"), Default("unowned"))
+//
+// Tag names are matched ignoring case, and values are trimmed and lowercased, so "Team: Data " and "team: data" are the same key.
+// None of them change the tags they're given.
+
+// Aggregators are given a copy of a resource's tags with these pseudo tags added, so rules can use more than tags
+const (
+	// NameTag is the resource's name, or its ID if it has no name
+	NameTag = "chanute:name"
+	// AccountTag is the account ID of the report, it's looked up with STS when aggregating
+	AccountTag = "chanute:account"
+)
+
+// aggregationTags copies tags, adding NameTag
+func aggregationTags(tags map[string]string, name, id string) map[string]string {
+	o := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		o[k] = v
+	}
+	if name == "" {
+		name = id
+	}
+	if name != "" {
+		o[NameTag] = name
+	}
+	return o
+}
+
+// withAccount adds AccountTag to the tags a is given
+func withAccount(a Aggregator, account string) Aggregator {
+	if a == nil || account == "" {
+		return a
+	}
+	return func(tags map[string]string) string {
+		o := make(map[string]string, len(tags)+1)
+		for k, v := range tags {
+			o[k] = v
+		}
+		o[AccountTag] = account
+		return a(o)
+	}
+}
+
+// FirstOf is the first non-empty key of aggregators
+func FirstOf(aggregators ...Aggregator) Aggregator {
+	return func(tags map[string]string) string {
+		for _, a := range aggregators {
+			if key := a(tags); key != "" {
+				return key
+			}
+		}
+		return ""
+	}
+}
+
+// NormalizedTag is the value of a tag
+func NormalizedTag(name string) Aggregator {
+	return func(tags map[string]string) string {
+		return normalizeKey(tagValue(tags, name))
+	}
+}
+
+// TagRegex is the first submatch of re in a tag's value, or the whole match if re has no groups
+func TagRegex(name string, re *regexp.Regexp) Aggregator {
+	return func(tags map[string]string) string {
+		m := re.FindStringSubmatch(tagValue(tags, name))
+		switch {
+		case len(m) > 1:
+			return normalizeKey(m[1])
+		case len(m) == 1:
+			return normalizeKey(m[0])
+		}
+		return ""
+	}
+}
+
+// ParsedKV reads a tag that holds several pairs, like "org=eng:::team=data", and is the value of key.
+// Pairs are split by sep, then the key and value by kvSep.
+func ParsedKV(name, sep, kvSep, key string) Aggregator {
+	return func(tags map[string]string) string {
+		for _, pair := range strings.Split(tagValue(tags, name), sep) {
+			kv := strings.SplitN(pair, kvSep, 2)
+			if len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), key) {
+				if v := normalizeKey(kv[1]); v != "" {
+					return v
+				}
+			}
+		}
+		return ""
+	}
+}
+
+// MapValues renames the keys of a, such as merging old team names into new ones. Keys that aren't in m are kept.
+func MapValues(a Aggregator, m map[string]string) Aggregator {
+	return func(tags map[string]string) string {
+		key := a(tags)
+		if mapped, ok := m[key]; ok {
+			return mapped
+		}
+		return key
+	}
+}
+
+// Default is always value, for the end of FirstOf
+func Default(value string) Aggregator {
+	return func(map[string]string) string {
+		return value
+	}
+}
+
+// tagValue is the tag called name, or the first of the tags with name in another case
+func tagValue(tags map[string]string, name string) string {
+	if v, ok := tags[name]; ok {
+		return v
+	}
+	match, found := "", false
+	for k := range tags {
+		if strings.EqualFold(k, name) && (!found || k < match) {
+			match, found = k, true
+		}
+	}
+	if !found {
+		return ""
+	}
+	return tags[match]
+}
+
+func normalizeKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,60 @@
+package chanute
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestAggregators(t *testing.T) {
+	tests := []struct {
+		name       string
+		aggregator Aggregator
+		tags       map[string]string
+		want       string
+	}{
+		{"normalized tag", NormalizedTag("team"), map[string]string{"team": " Data "}, "data"},
+		{"normalized tag in another case", NormalizedTag("team"), map[string]string{"Team": "Data"}, "data"},
+		{"exact case wins", NormalizedTag("team"), map[string]string{"TEAM": "a", "team": "b", "Team": "c"}, "b"},
+		{"other cases in order", NormalizedTag("team"), map[string]string{"TEAM": "a", "Team": "c"}, "a"},
+		{"missing tag", NormalizedTag("team"), map[string]string{"owner": "data"}, ""},
+		{"empty tag isn't another case's", NormalizedTag("team"), map[string]string{"": "data"}, ""},
+		{"nil tags", NormalizedTag("team"), nil, ""},
+
+		{"regex group", TagRegex("Name", regexp.MustCompile(`^([a-z]+)-`)), map[string]string{"name": "web-1"}, "web"},
+		{"regex without groups", TagRegex("Name", regexp.MustCompile(`^[A-Z]+`)), map[string]string{"Name": "WEB-1"}, "web"},
+		{"regex without a match", TagRegex("Name", regexp.MustCompile(`^([a-z]+)-`)), map[string]string{"Name": "1"}, ""},
+
+		{"parsed kv", ParsedKV("organization", ":::", "=", "team"), map[string]string{"organization": "org=eng:::team= Data "}, "data"},
+		{"parsed kv key in another case", ParsedKV("organization", ":::", "=", "team"), map[string]string{"Organization": "org=eng::: Team =data"}, "data"},
+		{"parsed kv empty value", ParsedKV("organization", ":::", "=", "team"), map[string]string{"organization": "team=:::team=data"}, "data"},
+		{"parsed kv without the key", ParsedKV("organization", ":::", "=", "team"), map[string]string{"organization": "org=eng"}, ""},
+		{"parsed kv value with separators", ParsedKV("organization", ";", ":", "team"), map[string]string{"organization": "team:a:b"}, "a:b"},
+
+		{"first of", FirstOf(NormalizedTag("team"), NormalizedTag("owner")), map[string]string{"owner": "data"}, "data"},
+		{"first of takes the first", FirstOf(NormalizedTag("team"), NormalizedTag("owner")), map[string]string{"team": "web", "owner": "data"}, "web"},
+		{"first of without keys", FirstOf(NormalizedTag("team")), nil, ""},
+		{"first of with default", FirstOf(NormalizedTag("team"), Default("unowned")), nil, "unowned"},
+
+		{"map values", MapValues(NormalizedTag("team"), map[string]string{"sre": "site reliability"}), map[string]string{"team": "SRE"}, "site reliability"},
+		{"map values keeps others", MapValues(NormalizedTag("team"), map[string]string{"sre": "site reliability"}), map[string]string{"team": "data"}, "data"},
+
+		{"name pseudo tag", NormalizedTag(NameTag), aggregationTags(nil, "Web-1", "i-1"), "web-1"},
+		{"name is the ID without a name", NormalizedTag(NameTag), aggregationTags(nil, "", "i-1"), "i-1"},
+		{"account pseudo tag", withAccount(NormalizedTag(AccountTag), "123456789012"), map[string]string{"team": "data"}, "123456789012"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.aggregator(test.tags); got != test.want {
+				t.Errorf("got %q, expected %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAggregatorsDontChangeTags(t *testing.T) {
+	tags := map[string]string{"team": "data"}
+	withAccount(NormalizedTag("team"), "123456789012")(aggregationTags(tags, "web-1", "i-1"))
+	if len(tags) != 1 {
+		t.Errorf("tags were changed to %v", tags)
+	}
+}
@@ -0,0 +1,90 @@
+package chanute
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// sessionPartition asks the session's endpoint resolver which partition its region is in
+func sessionPartition(sess *session.Session) string {
+	region := aws.StringValue(sess.Config.Region)
+	if sess.Config.EndpointResolver != nil {
+		if e, err := sess.Config.EndpointResolver.EndpointFor(sts.EndpointsID, region); err == nil && e.PartitionID != "" {
+			return e.PartitionID
+		}
+	}
+	return regionPartition(region)
+}
+
+// regionPartition is the partition of a region in the SDK's endpoints, or aws if it isn't known
+func regionPartition(region string) string {
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), regionOf(region)); ok {
+		return p.ID()
+	}
+	return endpoints.AwsPartitionID
+}
+
+// partition is c.Partition, or the partition of c.Region
+func (c *Clients) partition() string {
+	if c.Partition != "" {
+		return c.Partition
+	}
+	return regionPartition(c.Region)
+}
+
+// ResourceARN is the ARN of a resource the reports handle, from the ID or name Trusted Advisor reports.
+// The ARNs of v2 load balancers and Auto Scaling groups have IDs that only their APIs know, so they can't be built.
+func ResourceARN(partition string, kind TagKind, region, account, id string) (string, error) {
+	a := arn.ARN{Partition: partition, Region: regionOf(region), AccountID: account}
+	switch kind {
+	case TagKindEC2Instance:
+		a.Service, a.Resource = "ec2", "instance/"+id
+	case TagKindEBSVolume:
+		a.Service, a.Resource = "ec2", "volume/"+id
+	case TagKindSecurityGroup:
+		a.Service, a.Resource = "ec2", "security-group/"+id
+	case TagKindReservedInstances:
+		a.Service, a.Resource = "ec2", "reserved-instances/"+id
+	case TagKindRDSInstance:
+		a.Service, a.Resource = "rds", "db:"+id
+	case TagKindRedshiftCluster:
+		a.Service, a.Resource = "redshift", "cluster:"+id
+	case TagKindClassicLoadBalancer:
+		a.Service, a.Resource = "elasticloadbalancing", "loadbalancer/"+id
+	case TagKindS3Bucket:
+		a.Service, a.Region, a.AccountID, a.Resource = "s3", "", "", id
+	case TagKindIAMUser:
+		a.Service, a.Region, a.Resource = "iam", "", "user/"+id
+	default:
+		return "", errs.New("can't build the ARN of " + string(kind) + " " + id)
+	}
+	return a.String(), nil
+}
+
+// taggedResourceName is the kind and ID or name of a resource, from its ARN
+func taggedResourceName(resourceARN string) (TagKind, string, error) {
+	a, err := arn.Parse(resourceARN)
+	if err != nil {
+		return "", "", errs.Wrap(err)
+	}
+	i := strings.IndexAny(a.Resource, "/:")
+	if i < 0 {
+		return "", "", errs.New("no resource type in " + resourceARN)
+	}
+	kind, name := TagKind(a.Service+":"+a.Resource[:i]), a.Resource[i+1:]
+	if kind == TagKindLoadBalancer {
+		// v2 load balancers are loadbalancer/app/<name>/<id>, classic ones are loadbalancer/<name>
+		if parts := strings.Split(name, "/"); len(parts) == 3 {
+			name = parts[1]
+		} else {
+			kind = TagKindClassicLoadBalancer
+		}
+	}
+	return kind, name, nil
+}
@@ -0,0 +1,68 @@
+package chanute_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sheeley/chanute"
+)
+
+func TestResourceARN(t *testing.T) {
+	partitions := []struct {
+		partition, region string
+	}{
+		{"aws", "us-east-1"},
+		{"aws-cn", "cn-north-1"},
+		{"aws-us-gov", "us-gov-west-1"},
+	}
+	// the expected ARNs have the partition and region replaced
+	kinds := []struct {
+		kind chanute.TagKind
+		id   string
+		want string
+	}{
+		{chanute.TagKindEC2Instance, "i-1", "arn:PARTITION:ec2:REGION:123456789012:instance/i-1"},
+		{chanute.TagKindEBSVolume, "vol-1", "arn:PARTITION:ec2:REGION:123456789012:volume/vol-1"},
+		{chanute.TagKindSecurityGroup, "sg-1", "arn:PARTITION:ec2:REGION:123456789012:security-group/sg-1"},
+		{chanute.TagKindReservedInstances, "ri-1", "arn:PARTITION:ec2:REGION:123456789012:reserved-instances/ri-1"},
+		{chanute.TagKindRDSInstance, "orders", "arn:PARTITION:rds:REGION:123456789012:db:orders"},
+		{chanute.TagKindRedshiftCluster, "warehouse", "arn:PARTITION:redshift:REGION:123456789012:cluster:warehouse"},
+		{chanute.TagKindClassicLoadBalancer, "web", "arn:PARTITION:elasticloadbalancing:REGION:123456789012:loadbalancer/web"},
+		{chanute.TagKindS3Bucket, "logs", "arn:PARTITION:s3:::logs"},
+		{chanute.TagKindIAMUser, "deploy", "arn:PARTITION:iam::123456789012:user/deploy"},
+		// their ARNs have IDs that only their APIs know
+		{chanute.TagKindLoadBalancer, "app", ""},
+		{chanute.TagKindAutoScalingGroup, "workers", ""},
+		{"sqs:queue", "jobs", ""},
+	}
+	for _, p := range partitions {
+		for _, k := range kinds {
+			t.Run(p.partition+" "+string(k.kind), func(t *testing.T) {
+				want := strings.NewReplacer("PARTITION", p.partition, "REGION", p.region).Replace(k.want)
+				got, err := chanute.ResourceARN(p.partition, k.kind, p.region, "123456789012", k.id)
+				if want == "" {
+					if err == nil {
+						t.Errorf("expected an error, got %s", got)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("got %s, expected %s", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestResourceARNAvailabilityZone(t *testing.T) {
+	got, err := chanute.ResourceARN("aws", chanute.TagKindEBSVolume, "us-west-2b", "123456789012", "vol-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "arn:aws:ec2:us-west-2:123456789012:volume/vol-1"; got != want {
+		t.Errorf("got %s, expected %s", got, want)
+	}
+}
@@ -0,0 +1,180 @@
+package chanute
+
+import (
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/support"
+)
+
+type checkInfo struct {
+	ID       string
+	Category string
+}
+
+// checkCatalog has the stable Trusted Advisor ID of every check.
+// Display names have changed over time, so checks are matched on ID, and only fall back to the name for IDs that aren't listed.
+var checkCatalog = map[Check]checkInfo{
+	CheckAmazonEC2ReservedInstanceLeaseExpiration: {"1e93e4c0b5", "cost_optimizing"},
+	CheckAmazonEC2ReservedInstancesOptimization:   {"cX3c2R1chu", "cost_optimizing"},
+	CheckAmazonRDSIdleDBInstances:                 {"Ti39halfu8", "cost_optimizing"},
+	CheckAmazonRoute53LatencyResourceRecordSets:   {"51fC20e7I2", "cost_optimizing"},
+	CheckIdleLoadBalancers:                        {"hjLMh88uM8", "cost_optimizing"},
+	CheckLowUtilizationAmazonEC2Instances:         {"Qch7DwouX1", "cost_optimizing"},
+	CheckUnassociatedElasticIPAddresses:           {"Z4AUBRNSmz", "cost_optimizing"},
+	CheckUnderutilizedAmazonEBSVolumes:            {"DAvU99Dc4C", "cost_optimizing"},
+	CheckUnderutilizedAmazonRedshiftClusters:      {"G31sQ1E9U", "cost_optimizing"},
+
+	CheckAmazonEBSPublicSnapshots:                                  {"ePs02jT06w", "security"},
+	CheckAmazonRDSPublicSnapshots:                                  {"rSs93HQwa1", "security"},
+	CheckAmazonRDSSecurityGroupAccessRisk:                          {"nNauJisYIT", "security"},
+	CheckAmazonRoute53MXResourceRecordSetsandSenderPolicyFramework: {"c9D319e7sG", "security"},
+	CheckAmazonS3BucketPermissions:                                 {"Pfx0RwqBli", "security"},
+	CheckAWSCloudTrailLogging:                                      {"vjafUGJ9H0", "security"},
+	CheckCloudFrontCustomSSLCertificatesintheIAMCertificateStore:   {"N425c450f2", "security"},
+	CheckCloudFrontSSLCertificateontheOriginServer:                 {"N430c450f2", "security"},
+	CheckELBListenerSecurity:                                       {"a2sEc6ILx", "security"},
+	CheckELBSecurityGroups:                                         {"xSqX82fQu", "security"},
+	CheckExposedAccessKeys:                                         {"12Fnkpl8Y5", "security"},
+	CheckIAMAccessKeyRotation:                                      {"DqdJqYeRm5", "security"},
+	CheckIAMPasswordPolicy:                                         {"Yw2K9puPzl", "security"},
+	CheckIAMUse:                                                    {"zXCkfM1nI3", "security"},
+	CheckMFAonRootAccount:                                          {"7DAFEmoDos", "security"},
+	CheckSecurityGroupsSpecificPortsUnrestricted:                   {"HCP4007jGY", "security"},
+	CheckSecurityGroupsUnrestrictedAccess:                          {"1iG5NDGVre", "security"},
+
+	CheckAmazonAuroraDBInstanceAccessibility:        {"xuy7H1avtl", "fault_tolerance"},
+	CheckAmazonEBSSnapshots:                         {"H7IgTzjTYb", "fault_tolerance"},
+	CheckAmazonEC2AvailabilityZoneBalance:           {"wuy7G1zxql", "fault_tolerance"},
+	CheckAmazonRDSBackups:                           {"opQPADkZvH", "fault_tolerance"},
+	CheckAmazonRDSMultiAZ:                           {"f2iK5R6Dep", "fault_tolerance"},
+	CheckAmazonRoute53DeletedHealthChecks:           {"Cb877eB72b", "fault_tolerance"},
+	CheckAmazonRoute53FailoverResourceRecordSets:    {"b73EEdD790", "fault_tolerance"},
+	CheckAmazonRoute53HighTTLResourceRecordSets:     {"C056F80cR3", "fault_tolerance"},
+	CheckAmazonRoute53NameServerDelegations:         {"cF171Db240", "fault_tolerance"},
+	CheckAmazonS3BucketLogging:                      {"BueAdJ7NrP", "fault_tolerance"},
+	CheckAmazonS3BucketVersioning:                   {"R365s2Qddf", "fault_tolerance"},
+	CheckAutoScalingGroupHealthCheck:                {"CLOG40CDO8", "fault_tolerance"},
+	CheckAutoScalingGroupResources:                  {"8CNsSllI5v", "fault_tolerance"},
+	CheckAWSDirectConnectConnectionRedundancy:       {"0t121N1Ty3", "fault_tolerance"},
+	CheckAWSDirectConnectLocationRedundancy:         {"8M012Ph3U5", "fault_tolerance"},
+	CheckAWSDirectConnectVirtualInterfaceRedundancy: {"4g3Nt5M1Th", "fault_tolerance"},
+	CheckEC2ConfigServiceforEC2WindowsInstances:     {"V77iOLlBqz", "fault_tolerance"},
+	CheckELBConnectionDraining:                      {"7qGXsKIUw", "fault_tolerance"},
+	CheckELBCrossZoneLoadBalancing:                  {"xdeXZKIUy", "fault_tolerance"},
+	CheckENADriverVersionforEC2WindowsInstances:     {"TyfdMXG69d", "fault_tolerance"},
+	CheckLoadBalancerOptimization:                   {"iqdCTZKCUp", "fault_tolerance"},
+	CheckNVMeDriverVersionforEC2WindowsInstances:    {"yHAGQJV9K5", "fault_tolerance"},
+	CheckPVDriverVersionforEC2WindowsInstances:      {"Wnwm9Il5bG", "fault_tolerance"},
+	CheckVPNTunnelRedundancy:                        {"S45wrEXrLz", "fault_tolerance"},
+
+	CheckAmazonEBSProvisionedIOPSSSDVolumeAttachmentConfiguration: {"PPkZrjsH2q", "performance"},
+	CheckAmazonEC2toEBSThroughputOptimization:                     {"Bh2xRR2FGH", "performance"},
+	CheckAmazonRoute53AliasResourceRecordSets:                     {"B913Ef6fb4", "performance"},
+	CheckCloudFrontAlternateDomainNames:                           {"N420c450f2", "performance"},
+	CheckCloudFrontContentDeliveryOptimization:                    {"796d6f3D83", "performance"},
+	CheckCloudFrontHeaderForwardingandCacheHitRatio:               {"N415c450f2", "performance"},
+	CheckHighUtilizationAmazonEC2Instances:                        {"ZRxQlPsb6c", "performance"},
+	CheckLargeNumberofEC2SecurityGroupRulesAppliedtoanInstance:    {"j3DFqYTe29", "performance"},
+	CheckLargeNumberofRulesinanEC2SecurityGroup:                   {"tfg86AVHAZ", "performance"},
+	CheckOverutilizedAmazonEBSMagneticVolumes:                     {"k3J2hns32g", "performance"},
+
+	CheckAutoScalingGroups:                         {"fW7HH0l7J9", "service_limits"},
+	CheckAutoScalingLaunchConfigurations:           {"aW7HH0l7J9", "service_limits"},
+	CheckCloudFormationStacks:                      {"gW7HH0l7J9", "service_limits"},
+	CheckDynamoDBReadCapacity:                      {"6gtQddfEw6", "service_limits"},
+	CheckDynamoDBWriteCapacity:                     {"c5ftjdfkMr", "service_limits"},
+	CheckEBSActiveSnapshots:                        {"eI7KK0l7J9", "service_limits"},
+	CheckEBSActiveVolumes:                          {"fH7LL0l7J9", "service_limits"},
+	CheckEBSColdHDDSC1VolumeStorage:                {"gH5CC0e3J9", "service_limits"},
+	CheckEBSGeneralPurposeSSDGP2VolumeStorage:      {"dH7RR0l6J9", "service_limits"},
+	CheckEBSMagneticStandardVolumeStorage:          {"cG7HH0l7J9", "service_limits"},
+	CheckEBSProvisionedIOPSSSSDVolumeAggregateIOPS: {"tV7YY0l7J9", "service_limits"},
+	CheckEBSProvisionedIOPSSSDIO1VolumeStorage:     {"gI7MM0l7J9", "service_limits"},
+	CheckEBSThroughputOptimizedHDDST1VolumeStorage: {"wH7DD0l3J9", "service_limits"},
+	CheckEC2ElasticIPAddresses:                     {"aW9HH0l8J6", "service_limits"},
+	CheckEC2OnDemandInstances:                      {"0Xc6LMYG8P", "service_limits"},
+	CheckEC2ReservedInstanceLeases:                 {"iH7PP0l7J9", "service_limits"},
+	CheckELBApplicationLoadBalancers:               {"EM8b3yLRTr", "service_limits"},
+	CheckELBClassicLoadBalancers:                   {"iK7OO0l7J9", "service_limits"},
+	CheckELBNetworkLoadBalancers:                   {"8wIqYSt25K", "service_limits"},
+	CheckIAMGroup:                                  {"sU7XX0l7J9", "service_limits"},
+	CheckIAMInstanceProfiles:                       {"nO7SS0l7J9", "service_limits"},
+	CheckIAMPolicies:                               {"pR7UU0l7J9", "service_limits"},
+	CheckIAMRoles:                                  {"oQ7TT0l7J9", "service_limits"},
+	CheckIAMServerCertificates:                     {"rT7WW0l7J9", "service_limits"},
+	CheckIAMUsers:                                  {"qS7VV0l7J9", "service_limits"},
+	CheckKinesisShardsperRegion:                    {"bW7HH0l7J9", "service_limits"},
+	CheckRDSClusterParameterGroups:                 {"jtlIMO3qZM", "service_limits"},
+	CheckRDSClusterRoles:                           {"7fuccf1Mx7", "service_limits"},
+	CheckRDSClusters:                               {"gjqMBn6pjz", "service_limits"},
+	CheckRDSDBInstances:                            {"XG0aXHpIEt", "service_limits"},
+	CheckRDSDBManualSnapshots:                      {"dV84wpqRUs", "service_limits"},
+	CheckRDSDBParameterGroups:                      {"jEECYg2YVU", "service_limits"},
+	CheckRDSDBSecurityGroups:                       {"gfZAn3W7wl", "service_limits"},
+	CheckRDSEventSubscriptions:                     {"keAhfbH5yb", "service_limits"},
+	CheckRDSMaxAuthsperSecurityGroup:               {"dBkuNCvqn5", "service_limits"},
+	CheckRDSOptionGroups:                           {"3Njm0DJQO9", "service_limits"},
+	CheckRDSReadReplicasperMaster:                  {"pYW8UkYz2w", "service_limits"},
+	CheckRDSReservedInstances:                      {"UUDvOa5r34", "service_limits"},
+	CheckRDSSubnetGroups:                           {"dYWBaXaaMM", "service_limits"},
+	CheckRDSSubnetsperSubnetGroup:                  {"jEhCtdJKOY", "service_limits"},
+	CheckRDSTotalStorageQuota:                      {"P1jhKWEmLa", "service_limits"},
+	CheckRoute53HostedZones:                        {"dx3xfcdfMr", "service_limits"},
+	CheckRoute53MaxHealthChecks:                    {"ru4xfcdfMr", "service_limits"},
+	CheckRoute53ReusableDelegationSets:             {"ty3xfcdfMr", "service_limits"},
+	CheckRoute53TrafficPolicies:                    {"dx3xfbjfMr", "service_limits"},
+	CheckRoute53TrafficPolicyInstances:             {"dx8afcdfMr", "service_limits"},
+	CheckSESDailySendingQuota:                      {"hJ7NN0l7J9", "service_limits"},
+	CheckVPC:                                       {"jL7PP0l7J9", "service_limits"},
+	CheckVPCElasticIPAddress:                       {"lN7RR0l7J9", "service_limits"},
+	CheckVPCInternetGateways:                       {"kM7QQ0l7J9", "service_limits"},
+}
+
+var checksByID map[string]Check
+
+func init() {
+	checksByID = make(map[string]Check, len(checkCatalog))
+	for c, info := range checkCatalog {
+		checksByID[info.ID] = c
+	}
+}
+
+// ID is the check's stable Trusted Advisor ID, or "" if it isn't in the catalog
+func (c Check) ID() string {
+	return checkCatalog[c].ID
+}
+
+// Category is the check's Trusted Advisor category, such as cost_optimizing, or "" if it isn't in the catalog
+func (c Check) Category() string {
+	return checkCatalog[c].Category
+}
+
+// LookupCheck finds the Check for a Trusted Advisor check, by its ID first, and by name if the ID isn't in the catalog
+func LookupCheck(id, name string) Check {
+	if c, ok := checksByID[id]; ok {
+		return c
+	}
+	return Check(name)
+}
+
+func checkFor(ch *support.TrustedAdvisorCheckDescription) Check {
+	return LookupCheck(aws.StringValue(ch.Id), aws.StringValue(ch.Name))
+}
+
+// missingChecks are the active checks that Trusted Advisor didn't describe
+func missingChecks(activeChecks map[Check]bool, descriptions []*support.TrustedAdvisorCheckDescription, english map[string]*support.TrustedAdvisorCheckDescription) []Check {
+	found := make(map[Check]bool, len(descriptions))
+	for _, ch := range descriptions {
+		found[checkFor(englishFor(ch, english))] = true
+	}
+
+	var missing []Check
+	for c := range activeChecks {
+		if !found[c] {
+			missing = append(missing, c)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool { return missing[i] < missing[j] })
+	return missing
+}
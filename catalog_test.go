@@ -0,0 +1,57 @@
+package chanute_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+func TestLookupCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		id, text string
+		want     chanute.Check
+	}{
+		{"by ID", "Qch7DwouX1", string(chanute.CheckLowUtilizationAmazonEC2Instances), chanute.CheckLowUtilizationAmazonEC2Instances},
+		{"renamed", "Qch7DwouX1", "Amazon EC2 instances with low utilization", chanute.CheckLowUtilizationAmazonEC2Instances},
+		{"ID not in the catalog", "new-check", "A New Check", "A New Check"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := chanute.LookupCheck(test.id, test.text); got != test.want {
+				t.Errorf("got %q, expected %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReportMatchesChecks(t *testing.T) {
+	f := chanutetest.New()
+	f.Support.AddCheck("Ti39halfu8", "Idle Amazon RDS instances", "cost_optimizing", rdsIdleColumns,
+		[]string{"us-east-1", "orders", "No", "db.m5.large", "100", "14+", "$120"},
+	)
+	f.Support.AddCheck("new-check", "A New Check", "security", []string{"Region", "Resource"},
+		[]string{"us-east-1", "thing-1"},
+	)
+
+	r, err := chanute.GenerateClientReport(f.Clients(), chanute.WithChecks(
+		chanute.CheckAmazonRDSIdleDBInstances,
+		"A New Check",
+		chanute.CheckIdleLoadBalancers,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.CostOptimization == nil || r.CostOptimization.RDS == nil || len(r.CostOptimization.RDS.Instances) != 1 {
+		t.Errorf("expected the renamed RDS check to be matched by ID, got %+v", r.CostOptimization)
+	}
+	if g := r.GenericChecks["A New Check"]; g == nil || len(g.Resources) != 1 {
+		t.Errorf("expected the check that isn't in the catalog to be matched by name, got %+v", r.GenericChecks)
+	}
+	if want := []chanute.Check{chanute.CheckIdleLoadBalancers}; !reflect.DeepEqual(r.MissingChecks, want) {
+		t.Errorf("missing checks are %v, expected %v", r.MissingChecks, want)
+	}
+}
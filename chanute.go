@@ -1,23 +1,93 @@
 package chanute
 
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
 type Config struct {
-	GetTags             bool
+	// TagProvider looks up the tags of flagged resources, they have no tags without it
+	TagProvider         TagProvider
 	HideResourceDetails bool
 	Aggregator          Aggregator
 	Checks              []Check
+	// Concurrency is how many Trusted Advisor check results are fetched, and how many regions tags are looked up in, at once
+	Concurrency int
+	// EnvironmentConcurrency is how many environments of an aggregate report are generated at once
+	EnvironmentConcurrency int
+	// RefreshTimeout is how long to wait for checks to refresh, they aren't refreshed if it is 0
+	RefreshTimeout time.Duration
+	// RefreshPollInterval is how long to wait between refresh status checks, 5 seconds if it is 0
+	RefreshPollInterval time.Duration
+	SummaryOnly         bool
+	// Language is the language Trusted Advisor describes checks in
+	Language string
+	// IncludeSuppressed reports resources that are suppressed in Trusted Advisor in their own section, instead of leaving them out
+	IncludeSuppressed bool
 }
 
+const (
+	defaultConcurrency = 4
+	defaultLanguage    = "en"
+)
+
 type Aggregator func(map[string]string) string
 
 type Option func(*Config)
 
 func WithCustomTagAggregator(a Aggregator) Option {
 	return func(c *Config) {
-		c.GetTags = true
+		if c.TagProvider == nil {
+			c.TagProvider = &AWSTagProvider{}
+		}
 		c.Aggregator = a
 	}
 }
 
+// WithLanguage asks Trusted Advisor for check names and descriptions in another language, such as "ja".
+// Resources are still decoded by the English metadata columns, so the typed reports don't change.
+func WithLanguage(language string) Option {
+	return func(c *Config) {
+		c.Language = language
+	}
+}
+
+// WithSuppressedResources lists resources that are suppressed in Trusted Advisor in a separate section of each report.
+// They never count towards savings or aggregates. Each report's Suppressed field is only set with this option.
+func WithSuppressedResources() Option {
+	return func(c *Config) {
+		c.IncludeSuppressed = true
+	}
+}
+
+// WithResourceGroupsTaggingAPI looks up tags with the Resource Groups Tagging API, replacing any other TagProvider.
+// The per-service lookups are used for resources it doesn't support, or when it fails, such as without tag:GetResources permission.
+func WithResourceGroupsTaggingAPI() Option {
+	return WithTagProvider(&AWSTagProvider{TaggingAPI: true})
+}
+
+// WithTagProvider looks up tags with p instead of the AWS APIs, see ChainTagProviders to use more than one source
+func WithTagProvider(p TagProvider) Option {
+	return func(c *Config) {
+		c.TagProvider = p
+	}
+}
+
+// WithConcurrency sets how many Trusted Advisor check results are fetched at once
+func WithConcurrency(n int) Option {
+	return func(c *Config) {
+		c.Concurrency = n
+	}
+}
+
+// WithEnvironmentConcurrency sets how many environments GenerateAggregateReport generates at once
+func WithEnvironmentConcurrency(n int) Option {
+	return func(c *Config) {
+		c.EnvironmentConcurrency = n
+	}
+}
+
 func WithoutResourceDetails() Option {
 	return func(c *Config) {
 		c.HideResourceDetails = true
@@ -68,10 +138,10 @@ func WithServiceLimitChecks() Option {
 
 type TagMap map[string]map[string]string
 
-func stringPtrSet(ss []*string) map[*string]bool {
-	m := make(map[*string]bool, len(ss))
+func stringPtrSet(ss []*string) map[string]bool {
+	m := make(map[string]bool, len(ss))
 	for _, s := range ss {
-		m[s] = true
+		m[aws.StringValue(s)] = true
 	}
 	return m
 }
@@ -0,0 +1,54 @@
+package chanutetest
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+)
+
+// AutoScaling is a fake Auto Scaling API holding group tags.
+// Calling a method that isn't implemented panics.
+type AutoScaling struct {
+	autoscalingiface.AutoScalingAPI
+
+	Groups map[string][]*autoscaling.TagDescription
+}
+
+// AddGroup adds an Auto Scaling group with the given tags
+func (a *AutoScaling) AddGroup(name string, tags map[string]string) {
+	if a.Groups == nil {
+		a.Groups = map[string][]*autoscaling.TagDescription{}
+	}
+	var t []*autoscaling.TagDescription
+	for k, v := range tags {
+		t = append(t, &autoscaling.TagDescription{
+			Key:          aws.String(k),
+			Value:        aws.String(v),
+			ResourceId:   aws.String(name),
+			ResourceType: aws.String("auto-scaling-group"),
+		})
+	}
+	a.Groups[name] = t
+}
+
+// DescribeTags only supports the auto-scaling-group filter
+func (a *AutoScaling) DescribeTags(in *autoscaling.DescribeTagsInput) (*autoscaling.DescribeTagsOutput, error) {
+	o := &autoscaling.DescribeTagsOutput{}
+	for _, f := range in.Filters {
+		if aws.StringValue(f.Name) != "auto-scaling-group" {
+			continue
+		}
+		for _, n := range f.Values {
+			o.Tags = append(o.Tags, a.Groups[aws.StringValue(n)]...)
+		}
+	}
+	return o, nil
+}
+
+func (a *AutoScaling) DescribeTagsWithContext(ctx aws.Context, in *autoscaling.DescribeTagsInput, _ ...request.Option) (*autoscaling.DescribeTagsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return a.DescribeTags(in)
+}
@@ -0,0 +1,103 @@
+// Package chanutetest provides in-memory fakes of the AWS APIs used by chanute,
+// so reports, aggregators and report consumers can be exercised without credentials.
+package chanutetest
+
+import (
+	"sync"
+
+	"github.com/sheeley/chanute"
+)
+
+// Fakes is a complete set of fake clients
+type Fakes struct {
+	Region string
+
+	Support     *Support
+	EC2         *EC2
+	ELBv2       *ELBv2
+	RDS         *RDS
+	Redshift    *Redshift
+	STS         *STS
+	IAM         *IAM
+	S3          *S3
+	ELB         *ELB
+	AutoScaling *AutoScaling
+
+	ResourceGroupsTagging *ResourceGroupsTagging
+
+	// Regions are the fakes for other regions, made by InRegion
+	Regions map[string]*Fakes
+
+	mu   sync.Mutex
+	root *Fakes
+}
+
+// New returns empty fakes for us-east-1 and account 123456789012
+func New() *Fakes {
+	return &Fakes{
+		Region: "us-east-1",
+
+		Support:     &Support{},
+		EC2:         &EC2{},
+		ELBv2:       &ELBv2{},
+		RDS:         &RDS{},
+		Redshift:    &Redshift{},
+		STS:         &STS{Account: "123456789012"},
+		IAM:         &IAM{},
+		S3:          &S3{},
+		ELB:         &ELB{},
+		AutoScaling: &AutoScaling{},
+
+		ResourceGroupsTagging: &ResourceGroupsTagging{},
+	}
+}
+
+// InRegion returns the fakes for another region, they share Support and STS with f
+func (f *Fakes) InRegion(region string) *Fakes {
+	if f.root != nil {
+		return f.root.InRegion(region)
+	}
+	if region == f.Region {
+		return f
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if rf, ok := f.Regions[region]; ok {
+		return rf
+	}
+	rf := New()
+	rf.Region = region
+	rf.Support = f.Support
+	rf.STS = f.STS
+	rf.root = f
+	if f.Regions == nil {
+		f.Regions = map[string]*Fakes{}
+	}
+	f.Regions[region] = rf
+	return rf
+}
+
+// Clients returns the fakes as chanute clients, ready for chanute.GenerateClientReport
+func (f *Fakes) Clients() *chanute.Clients {
+	return &chanute.Clients{
+		Region: f.Region,
+
+		Support:     f.Support,
+		EC2:         f.EC2,
+		ELBv2:       f.ELBv2,
+		RDS:         f.RDS,
+		Redshift:    f.Redshift,
+		STS:         f.STS,
+		IAM:         f.IAM,
+		S3:          f.S3,
+		ELB:         f.ELB,
+		AutoScaling: f.AutoScaling,
+
+		ResourceGroupsTagging: f.ResourceGroupsTagging,
+
+		NewRegion: func(region string) *chanute.Clients {
+			return f.InRegion(region).Clients()
+		},
+	}
+}
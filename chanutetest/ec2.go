@@ -0,0 +1,168 @@
+package chanutetest
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+)
+
+// EC2 is a fake EC2 API holding instances, volumes, security groups and reserved instances.
+// Calling a method that isn't implemented panics.
+type EC2 struct {
+	ec2iface.EC2API
+
+	Instances         []*ec2.Instance
+	Volumes           []*ec2.Volume
+	SecurityGroups    []*ec2.SecurityGroup
+	ReservedInstances []*ec2.ReservedInstances
+}
+
+// AddInstance adds an instance with the given tags
+func (e *EC2) AddInstance(id string, tags map[string]string) {
+	e.Instances = append(e.Instances, &ec2.Instance{InstanceId: aws.String(id), Tags: ec2Tags(tags)})
+}
+
+// AddVolume adds a volume with the given tags
+func (e *EC2) AddVolume(id string, tags map[string]string) {
+	e.Volumes = append(e.Volumes, &ec2.Volume{VolumeId: aws.String(id), Tags: ec2Tags(tags)})
+}
+
+// AddSecurityGroup adds a security group with the given tags
+func (e *EC2) AddSecurityGroup(id string, tags map[string]string) {
+	e.SecurityGroups = append(e.SecurityGroups, &ec2.SecurityGroup{GroupId: aws.String(id), Tags: ec2Tags(tags)})
+}
+
+// AddReservedInstances adds reserved instances with the given tags
+func (e *EC2) AddReservedInstances(id string, tags map[string]string) {
+	e.ReservedInstances = append(e.ReservedInstances, &ec2.ReservedInstances{ReservedInstancesId: aws.String(id), Tags: ec2Tags(tags)})
+}
+
+func (e *EC2) DescribeInstances(in *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	byID := make(map[string]*ec2.Instance, len(e.Instances))
+	for _, i := range e.Instances {
+		byID[aws.StringValue(i.InstanceId)] = i
+	}
+
+	res := &ec2.Reservation{}
+	var missing []string
+	for _, id := range aws.StringValueSlice(in.InstanceIds) {
+		i, ok := byID[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		res.Instances = append(res.Instances, i)
+	}
+	if len(missing) > 0 {
+		return nil, awserr.New("InvalidInstanceID.NotFound", "The instance IDs '"+strings.Join(missing, ", ")+"' do not exist", nil)
+	}
+	if len(in.InstanceIds) == 0 {
+		res.Instances = e.Instances
+	}
+	return &ec2.DescribeInstancesOutput{Reservations: []*ec2.Reservation{res}}, nil
+}
+
+func (e *EC2) DescribeVolumes(in *ec2.DescribeVolumesInput) (*ec2.DescribeVolumesOutput, error) {
+	byID := make(map[string]*ec2.Volume, len(e.Volumes))
+	for _, v := range e.Volumes {
+		byID[aws.StringValue(v.VolumeId)] = v
+	}
+
+	o := &ec2.DescribeVolumesOutput{}
+	var missing []string
+	for _, id := range aws.StringValueSlice(in.VolumeIds) {
+		v, ok := byID[id]
+		if !ok {
+			missing = append(missing, id)
+			continue
+		}
+		o.Volumes = append(o.Volumes, v)
+	}
+	if len(missing) > 0 {
+		return nil, awserr.New("InvalidVolume.NotFound", "The volume '"+strings.Join(missing, ", ")+"' does not exist.", nil)
+	}
+	if len(in.VolumeIds) == 0 {
+		o.Volumes = e.Volumes
+	}
+	return o, nil
+}
+
+// DescribeSecurityGroups only supports the group-id filter
+func (e *EC2) DescribeSecurityGroups(in *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	ids := map[string]bool{}
+	for _, f := range in.Filters {
+		if aws.StringValue(f.Name) == "group-id" {
+			for _, id := range aws.StringValueSlice(f.Values) {
+				ids[id] = true
+			}
+		}
+	}
+
+	o := &ec2.DescribeSecurityGroupsOutput{}
+	for _, sg := range e.SecurityGroups {
+		if len(ids) == 0 || ids[aws.StringValue(sg.GroupId)] {
+			o.SecurityGroups = append(o.SecurityGroups, sg)
+		}
+	}
+	return o, nil
+}
+
+// DescribeReservedInstances only supports the reserved-instances-id filter
+func (e *EC2) DescribeReservedInstances(in *ec2.DescribeReservedInstancesInput) (*ec2.DescribeReservedInstancesOutput, error) {
+	ids := map[string]bool{}
+	for _, f := range in.Filters {
+		if aws.StringValue(f.Name) == "reserved-instances-id" {
+			for _, id := range aws.StringValueSlice(f.Values) {
+				ids[id] = true
+			}
+		}
+	}
+
+	o := &ec2.DescribeReservedInstancesOutput{}
+	for _, ri := range e.ReservedInstances {
+		if len(ids) == 0 || ids[aws.StringValue(ri.ReservedInstancesId)] {
+			o.ReservedInstances = append(o.ReservedInstances, ri)
+		}
+	}
+	return o, nil
+}
+
+func ec2Tags(tags map[string]string) []*ec2.Tag {
+	var o []*ec2.Tag
+	for k, v := range tags {
+		o = append(o, &ec2.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	return o
+}
+
+func (e *EC2) DescribeInstancesWithContext(ctx aws.Context, in *ec2.DescribeInstancesInput, _ ...request.Option) (*ec2.DescribeInstancesOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.DescribeInstances(in)
+}
+
+func (e *EC2) DescribeVolumesWithContext(ctx aws.Context, in *ec2.DescribeVolumesInput, _ ...request.Option) (*ec2.DescribeVolumesOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.DescribeVolumes(in)
+}
+
+func (e *EC2) DescribeSecurityGroupsWithContext(ctx aws.Context, in *ec2.DescribeSecurityGroupsInput, _ ...request.Option) (*ec2.DescribeSecurityGroupsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.DescribeSecurityGroups(in)
+}
+
+func (e *EC2) DescribeReservedInstancesWithContext(ctx aws.Context, in *ec2.DescribeReservedInstancesInput, _ ...request.Option) (*ec2.DescribeReservedInstancesOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.DescribeReservedInstances(in)
+}
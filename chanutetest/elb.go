@@ -0,0 +1,49 @@
+package chanutetest
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+)
+
+// ELB is a fake classic load balancer API holding load balancer tags.
+// Calling a method that isn't implemented panics.
+type ELB struct {
+	elbiface.ELBAPI
+
+	LoadBalancers map[string][]*elb.Tag
+}
+
+// AddLoadBalancer adds a classic load balancer with the given tags
+func (e *ELB) AddLoadBalancer(name string, tags map[string]string) {
+	if e.LoadBalancers == nil {
+		e.LoadBalancers = map[string][]*elb.Tag{}
+	}
+	var t []*elb.Tag
+	for k, v := range tags {
+		t = append(t, &elb.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	e.LoadBalancers[name] = t
+}
+
+// DescribeTags fails if any of the load balancers don't exist, like the real API
+func (e *ELB) DescribeTags(in *elb.DescribeTagsInput) (*elb.DescribeTagsOutput, error) {
+	o := &elb.DescribeTagsOutput{}
+	for _, n := range in.LoadBalancerNames {
+		tags, ok := e.LoadBalancers[aws.StringValue(n)]
+		if !ok {
+			return nil, awserr.New(elb.ErrCodeAccessPointNotFoundException, "There is no ACTIVE Load Balancer named '"+aws.StringValue(n)+"'", nil)
+		}
+		o.TagDescriptions = append(o.TagDescriptions, &elb.TagDescription{LoadBalancerName: n, Tags: tags})
+	}
+	return o, nil
+}
+
+func (e *ELB) DescribeTagsWithContext(ctx aws.Context, in *elb.DescribeTagsInput, _ ...request.Option) (*elb.DescribeTagsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.DescribeTags(in)
+}
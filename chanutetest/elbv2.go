@@ -0,0 +1,100 @@
+package chanutetest
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+)
+
+// ELBv2 is a fake load balancer API.
+// Calling a method that isn't implemented panics.
+type ELBv2 struct {
+	elbv2iface.ELBV2API
+
+	LoadBalancers []*elbv2.LoadBalancer
+	// Tags are keyed by load balancer ARN
+	Tags map[string][]*elbv2.Tag
+}
+
+// AddLoadBalancer adds a load balancer with the given tags
+func (e *ELBv2) AddLoadBalancer(name, arn string, tags map[string]string) {
+	e.LoadBalancers = append(e.LoadBalancers, &elbv2.LoadBalancer{
+		LoadBalancerName: aws.String(name),
+		LoadBalancerArn:  aws.String(arn),
+	})
+	if e.Tags == nil {
+		e.Tags = map[string][]*elbv2.Tag{}
+	}
+	for k, v := range tags {
+		e.Tags[arn] = append(e.Tags[arn], &elbv2.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+}
+
+func (e *ELBv2) DescribeLoadBalancers(in *elbv2.DescribeLoadBalancersInput) (*elbv2.DescribeLoadBalancersOutput, error) {
+	byName := make(map[string]*elbv2.LoadBalancer, len(e.LoadBalancers))
+	for _, lb := range e.LoadBalancers {
+		byName[aws.StringValue(lb.LoadBalancerName)] = lb
+	}
+
+	o := &elbv2.DescribeLoadBalancersOutput{}
+	var missing []string
+	for _, name := range aws.StringValueSlice(in.Names) {
+		lb, ok := byName[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		o.LoadBalancers = append(o.LoadBalancers, lb)
+	}
+	if len(missing) > 0 {
+		return nil, awserr.New("LoadBalancerNotFound", "Load balancers '["+strings.Join(missing, ", ")+"]' not found", nil)
+	}
+	if len(in.Names) == 0 {
+		o.LoadBalancers = e.LoadBalancers
+	}
+	return o, nil
+}
+
+// DescribeTags fails if any of the load balancers don't exist, like the real API
+func (e *ELBv2) DescribeTags(in *elbv2.DescribeTagsInput) (*elbv2.DescribeTagsOutput, error) {
+	exists := make(map[string]bool, len(e.LoadBalancers))
+	for _, lb := range e.LoadBalancers {
+		exists[aws.StringValue(lb.LoadBalancerArn)] = true
+	}
+	var missing []string
+	for _, arn := range in.ResourceArns {
+		if !exists[aws.StringValue(arn)] {
+			missing = append(missing, aws.StringValue(arn))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, awserr.New("LoadBalancerNotFound", "Load balancers '["+strings.Join(missing, ", ")+"]' not found", nil)
+	}
+
+	o := &elbv2.DescribeTagsOutput{}
+	for _, arn := range in.ResourceArns {
+		o.TagDescriptions = append(o.TagDescriptions, &elbv2.TagDescription{
+			ResourceArn: arn,
+			Tags:        e.Tags[aws.StringValue(arn)],
+		})
+	}
+	return o, nil
+}
+
+func (e *ELBv2) DescribeLoadBalancersWithContext(ctx aws.Context, in *elbv2.DescribeLoadBalancersInput, _ ...request.Option) (*elbv2.DescribeLoadBalancersOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.DescribeLoadBalancers(in)
+}
+
+func (e *ELBv2) DescribeTagsWithContext(ctx aws.Context, in *elbv2.DescribeTagsInput, _ ...request.Option) (*elbv2.DescribeTagsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.DescribeTags(in)
+}
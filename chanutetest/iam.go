@@ -0,0 +1,44 @@
+package chanutetest
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+)
+
+// IAM is a fake IAM API holding user tags.
+// Calling a method that isn't implemented panics.
+type IAM struct {
+	iamiface.IAMAPI
+
+	Users map[string][]*iam.Tag
+}
+
+// AddUser adds a user with the given tags
+func (i *IAM) AddUser(name string, tags map[string]string) {
+	if i.Users == nil {
+		i.Users = map[string][]*iam.Tag{}
+	}
+	var t []*iam.Tag
+	for k, v := range tags {
+		t = append(t, &iam.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	i.Users[name] = t
+}
+
+func (i *IAM) ListUserTags(in *iam.ListUserTagsInput) (*iam.ListUserTagsOutput, error) {
+	tags, ok := i.Users[aws.StringValue(in.UserName)]
+	if !ok {
+		return nil, awserr.New(iam.ErrCodeNoSuchEntityException, "The user with name "+aws.StringValue(in.UserName)+" cannot be found.", nil)
+	}
+	return &iam.ListUserTagsOutput{Tags: tags, IsTruncated: aws.Bool(false)}, nil
+}
+
+func (i *IAM) ListUserTagsWithContext(ctx aws.Context, in *iam.ListUserTagsInput, _ ...request.Option) (*iam.ListUserTagsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return i.ListUserTags(in)
+}
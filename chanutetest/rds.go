@@ -0,0 +1,44 @@
+package chanutetest
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+)
+
+// RDS is a fake RDS API.
+// Calling a method that isn't implemented panics.
+type RDS struct {
+	rdsiface.RDSAPI
+
+	// Tags are keyed by DB instance ARN
+	Tags map[string][]*rds.Tag
+}
+
+// AddInstance adds tags for the DB instance with the given ARN
+func (r *RDS) AddInstance(arn string, tags map[string]string) {
+	if r.Tags == nil {
+		r.Tags = map[string][]*rds.Tag{}
+	}
+	r.Tags[arn] = []*rds.Tag{}
+	for k, v := range tags {
+		r.Tags[arn] = append(r.Tags[arn], &rds.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+}
+
+func (r *RDS) ListTagsForResource(in *rds.ListTagsForResourceInput) (*rds.ListTagsForResourceOutput, error) {
+	tags, ok := r.Tags[aws.StringValue(in.ResourceName)]
+	if !ok {
+		return nil, awserr.New("DBInstanceNotFound", aws.StringValue(in.ResourceName)+" not found", nil)
+	}
+	return &rds.ListTagsForResourceOutput{TagList: tags}, nil
+}
+
+func (r *RDS) ListTagsForResourceWithContext(ctx aws.Context, in *rds.ListTagsForResourceInput, _ ...request.Option) (*rds.ListTagsForResourceOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.ListTagsForResource(in)
+}
@@ -0,0 +1,36 @@
+package chanutetest
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/redshift"
+	"github.com/aws/aws-sdk-go/service/redshift/redshiftiface"
+)
+
+// Redshift is a fake Redshift API.
+// Calling a method that isn't implemented panics.
+type Redshift struct {
+	redshiftiface.RedshiftAPI
+
+	Clusters []*redshift.Cluster
+}
+
+// AddCluster adds a cluster with the given tags
+func (r *Redshift) AddCluster(id string, tags map[string]string) {
+	c := &redshift.Cluster{ClusterIdentifier: aws.String(id)}
+	for k, v := range tags {
+		c.Tags = append(c.Tags, &redshift.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	r.Clusters = append(r.Clusters, c)
+}
+
+func (r *Redshift) DescribeClusters(in *redshift.DescribeClustersInput) (*redshift.DescribeClustersOutput, error) {
+	return &redshift.DescribeClustersOutput{Clusters: r.Clusters}, nil
+}
+
+func (r *Redshift) DescribeClustersWithContext(ctx aws.Context, in *redshift.DescribeClustersInput, _ ...request.Option) (*redshift.DescribeClustersOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.DescribeClusters(in)
+}
@@ -0,0 +1,47 @@
+package chanutetest
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// S3 is a fake S3 API holding bucket tags.
+// Calling a method that isn't implemented panics.
+type S3 struct {
+	s3iface.S3API
+
+	Buckets map[string][]*s3.Tag
+}
+
+// AddBucket adds a bucket with the given tags, a bucket without tags has no tag set
+func (s *S3) AddBucket(name string, tags map[string]string) {
+	if s.Buckets == nil {
+		s.Buckets = map[string][]*s3.Tag{}
+	}
+	var t []*s3.Tag
+	for k, v := range tags {
+		t = append(t, &s3.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	s.Buckets[name] = t
+}
+
+func (s *S3) GetBucketTagging(in *s3.GetBucketTaggingInput) (*s3.GetBucketTaggingOutput, error) {
+	tags, ok := s.Buckets[aws.StringValue(in.Bucket)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchBucket, "The specified bucket does not exist", nil)
+	}
+	if len(tags) == 0 {
+		return nil, awserr.New("NoSuchTagSet", "The TagSet does not exist", nil)
+	}
+	return &s3.GetBucketTaggingOutput{TagSet: tags}, nil
+}
+
+func (s *S3) GetBucketTaggingWithContext(ctx aws.Context, in *s3.GetBucketTaggingInput, _ ...request.Option) (*s3.GetBucketTaggingOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.GetBucketTagging(in)
+}
@@ -0,0 +1,31 @@
+package chanutetest
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+)
+
+// STS is a fake STS API that reports a fixed account.
+// Calling a method that isn't implemented panics.
+type STS struct {
+	stsiface.STSAPI
+
+	Account string
+}
+
+func (s *STS) GetCallerIdentity(in *sts.GetCallerIdentityInput) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{
+		Account: aws.String(s.Account),
+		Arn:     aws.String("arn:aws:iam::" + s.Account + ":user/chanutetest"),
+		UserId:  aws.String("chanutetest"),
+	}, nil
+}
+
+func (s *STS) GetCallerIdentityWithContext(ctx aws.Context, in *sts.GetCallerIdentityInput, _ ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.GetCallerIdentity(in)
+}
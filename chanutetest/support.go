@@ -0,0 +1,225 @@
+package chanutetest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/aws/aws-sdk-go/service/support/supportiface"
+)
+
+// Support is a fake Trusted Advisor.
+// Calling a method that isn't implemented panics.
+type Support struct {
+	supportiface.SupportAPI
+
+	Checks  []*support.TrustedAdvisorCheckDescription
+	Results map[string]*support.TrustedAdvisorCheckResult
+	// Translations are the check descriptions in languages other than English, by language and then check ID
+	Translations map[string]map[string]*support.TrustedAdvisorCheckDescription
+
+	// RefreshStatuses are reported for checks once they've been refreshed, "success" if unset
+	RefreshStatuses map[string]string
+	// RefreshErrors are returned when refreshing a check
+	RefreshErrors map[string]error
+	// Refreshed records which checks have been refreshed
+	Refreshed map[string]bool
+	// RefreshCooldowns are how long until checks can be refreshed again, they are zero if unset
+	RefreshCooldowns map[string]time.Duration
+	// Savings are the estimated monthly savings reported in cost optimization check summaries
+	Savings map[string]float64
+
+	mu sync.Mutex
+}
+
+// AddCheck registers a check with the given metadata columns, and a result with one flagged resource per row.
+// The result status is "warning" if there are any rows, otherwise "ok".
+func (s *Support) AddCheck(id, name, category string, columns []string, rows ...[]string) *support.TrustedAdvisorCheckResult {
+	s.Checks = append(s.Checks, &support.TrustedAdvisorCheckDescription{
+		Id:       aws.String(id),
+		Name:     aws.String(name),
+		Category: aws.String(category),
+		Metadata: aws.StringSlice(columns),
+	})
+
+	status := "ok"
+	if len(rows) > 0 {
+		status = "warning"
+	}
+
+	res := &support.TrustedAdvisorCheckResult{
+		CheckId:   aws.String(id),
+		Status:    aws.String(status),
+		Timestamp: aws.String("2020-01-01T00:00:00Z"),
+		ResourcesSummary: &support.TrustedAdvisorResourcesSummary{
+			ResourcesFlagged:    aws.Int64(int64(len(rows))),
+			ResourcesProcessed:  aws.Int64(int64(len(rows))),
+			ResourcesIgnored:    aws.Int64(0),
+			ResourcesSuppressed: aws.Int64(0),
+		},
+	}
+	for _, row := range rows {
+		res.FlaggedResources = append(res.FlaggedResources, &support.TrustedAdvisorResourceDetail{
+			IsSuppressed: aws.Bool(false),
+			Metadata:     aws.StringSlice(row),
+			Status:       aws.String("warning"),
+		})
+	}
+
+	if s.Results == nil {
+		s.Results = map[string]*support.TrustedAdvisorCheckResult{}
+	}
+	s.Results[id] = res
+	return res
+}
+
+// AddTranslation describes a check that was added with AddCheck in another language.
+// Checks without a translation are described in English.
+func (s *Support) AddTranslation(language, id, name string, columns []string) {
+	if s.Translations == nil {
+		s.Translations = map[string]map[string]*support.TrustedAdvisorCheckDescription{}
+	}
+	if s.Translations[language] == nil {
+		s.Translations[language] = map[string]*support.TrustedAdvisorCheckDescription{}
+	}
+	s.Translations[language][id] = &support.TrustedAdvisorCheckDescription{
+		Id:       aws.String(id),
+		Name:     aws.String(name),
+		Metadata: aws.StringSlice(columns),
+	}
+}
+
+func (s *Support) DescribeTrustedAdvisorChecks(in *support.DescribeTrustedAdvisorChecksInput) (*support.DescribeTrustedAdvisorChecksOutput, error) {
+	translations := s.Translations[aws.StringValue(in.Language)]
+	if len(translations) == 0 {
+		return &support.DescribeTrustedAdvisorChecksOutput{Checks: s.Checks}, nil
+	}
+
+	o := &support.DescribeTrustedAdvisorChecksOutput{}
+	for _, ch := range s.Checks {
+		if tr, ok := translations[aws.StringValue(ch.Id)]; ok {
+			ch = &support.TrustedAdvisorCheckDescription{
+				Id:          ch.Id,
+				Name:        tr.Name,
+				Category:    ch.Category,
+				Description: tr.Description,
+				Metadata:    tr.Metadata,
+			}
+		}
+		o.Checks = append(o.Checks, ch)
+	}
+	return o, nil
+}
+
+func (s *Support) DescribeTrustedAdvisorCheckResult(in *support.DescribeTrustedAdvisorCheckResultInput) (*support.DescribeTrustedAdvisorCheckResultOutput, error) {
+	res, ok := s.Results[aws.StringValue(in.CheckId)]
+	if !ok {
+		return nil, awserr.New("InvalidParameterValueException", "check "+aws.StringValue(in.CheckId)+" does not exist", nil)
+	}
+	return &support.DescribeTrustedAdvisorCheckResultOutput{Result: res}, nil
+}
+
+func (s *Support) RefreshTrustedAdvisorCheck(in *support.RefreshTrustedAdvisorCheckInput) (*support.RefreshTrustedAdvisorCheckOutput, error) {
+	id := aws.StringValue(in.CheckId)
+	if err := s.RefreshErrors[id]; err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Refreshed == nil {
+		s.Refreshed = map[string]bool{}
+	}
+	s.Refreshed[id] = true
+	return &support.RefreshTrustedAdvisorCheckOutput{Status: &support.TrustedAdvisorCheckRefreshStatus{
+		CheckId:                    in.CheckId,
+		MillisUntilNextRefreshable: aws.Int64(0),
+		Status:                     aws.String("enqueued"),
+	}}, nil
+}
+
+func (s *Support) DescribeTrustedAdvisorCheckRefreshStatuses(in *support.DescribeTrustedAdvisorCheckRefreshStatusesInput) (*support.DescribeTrustedAdvisorCheckRefreshStatusesOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o := &support.DescribeTrustedAdvisorCheckRefreshStatusesOutput{}
+	for _, id := range aws.StringValueSlice(in.CheckIds) {
+		status := "none"
+		if s.Refreshed[id] {
+			status = "success"
+			if sts, ok := s.RefreshStatuses[id]; ok {
+				status = sts
+			}
+		}
+		o.Statuses = append(o.Statuses, &support.TrustedAdvisorCheckRefreshStatus{
+			CheckId:                    aws.String(id),
+			MillisUntilNextRefreshable: aws.Int64(int64(s.RefreshCooldowns[id] / time.Millisecond)),
+			Status:                     aws.String(status),
+		})
+	}
+	return o, nil
+}
+
+func (s *Support) DescribeTrustedAdvisorCheckSummaries(in *support.DescribeTrustedAdvisorCheckSummariesInput) (*support.DescribeTrustedAdvisorCheckSummariesOutput, error) {
+	o := &support.DescribeTrustedAdvisorCheckSummariesOutput{}
+	for _, id := range aws.StringValueSlice(in.CheckIds) {
+		res, ok := s.Results[id]
+		if !ok {
+			return nil, awserr.New("InvalidParameterValueException", "check "+id+" does not exist", nil)
+		}
+		sum := &support.TrustedAdvisorCheckSummary{
+			CategorySpecificSummary: &support.TrustedAdvisorCategorySpecificSummary{},
+			CheckId:                 res.CheckId,
+			HasFlaggedResources:     aws.Bool(len(res.FlaggedResources) > 0),
+			ResourcesSummary:        res.ResourcesSummary,
+			Status:                  res.Status,
+			Timestamp:               res.Timestamp,
+		}
+		if savings, ok := s.Savings[id]; ok {
+			sum.CategorySpecificSummary.CostOptimizing = &support.TrustedAdvisorCostOptimizingSummary{
+				EstimatedMonthlySavings:        aws.Float64(savings),
+				EstimatedPercentMonthlySavings: aws.Float64(0),
+			}
+		}
+		o.Summaries = append(o.Summaries, sum)
+	}
+	return o, nil
+}
+
+func (s *Support) DescribeTrustedAdvisorChecksWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorChecksInput, _ ...request.Option) (*support.DescribeTrustedAdvisorChecksOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.DescribeTrustedAdvisorChecks(in)
+}
+
+func (s *Support) DescribeTrustedAdvisorCheckResultWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckResultInput, _ ...request.Option) (*support.DescribeTrustedAdvisorCheckResultOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.DescribeTrustedAdvisorCheckResult(in)
+}
+
+func (s *Support) RefreshTrustedAdvisorCheckWithContext(ctx aws.Context, in *support.RefreshTrustedAdvisorCheckInput, _ ...request.Option) (*support.RefreshTrustedAdvisorCheckOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.RefreshTrustedAdvisorCheck(in)
+}
+
+func (s *Support) DescribeTrustedAdvisorCheckRefreshStatusesWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckRefreshStatusesInput, _ ...request.Option) (*support.DescribeTrustedAdvisorCheckRefreshStatusesOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.DescribeTrustedAdvisorCheckRefreshStatuses(in)
+}
+
+func (s *Support) DescribeTrustedAdvisorCheckSummariesWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckSummariesInput, _ ...request.Option) (*support.DescribeTrustedAdvisorCheckSummariesOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.DescribeTrustedAdvisorCheckSummaries(in)
+}
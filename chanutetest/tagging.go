@@ -0,0 +1,83 @@
+package chanutetest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+)
+
+// ResourceGroupsTagging is a fake Resource Groups Tagging API holding resources by ARN.
+// It's independent of the other fakes, so a resource's tags can differ between the two like they can in AWS.
+// Calling a method that isn't implemented panics.
+type ResourceGroupsTagging struct {
+	resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+
+	Resources []*resourcegroupstaggingapi.ResourceTagMapping
+	// PageSize is how many resources are returned per page, all of them if it is 0
+	PageSize int
+}
+
+// AddResource adds a resource with the given tags
+func (t *ResourceGroupsTagging) AddResource(arn string, tags map[string]string) {
+	m := &resourcegroupstaggingapi.ResourceTagMapping{ResourceARN: aws.String(arn)}
+	for k, v := range tags {
+		m.Tags = append(m.Tags, &resourcegroupstaggingapi.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	t.Resources = append(t.Resources, m)
+}
+
+// GetResources supports resource type filters and pagination, but not tag filters
+func (t *ResourceGroupsTagging) GetResources(in *resourcegroupstaggingapi.GetResourcesInput) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	var matched []*resourcegroupstaggingapi.ResourceTagMapping
+	for _, r := range t.Resources {
+		if matchesResourceType(aws.StringValue(r.ResourceARN), aws.StringValueSlice(in.ResourceTypeFilters)) {
+			matched = append(matched, r)
+		}
+	}
+
+	start, _ := strconv.Atoi(aws.StringValue(in.PaginationToken))
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := len(matched)
+	if t.PageSize > 0 && start+t.PageSize < end {
+		end = start + t.PageSize
+	}
+	o := &resourcegroupstaggingapi.GetResourcesOutput{ResourceTagMappingList: matched[start:end]}
+	if end < len(matched) {
+		o.PaginationToken = aws.String(strconv.Itoa(end))
+	}
+	return o, nil
+}
+
+// matchesResourceType compares "service" or "service:type" filters with an ARN
+func matchesResourceType(arn string, filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return false
+	}
+	service, resource := parts[2], parts[5]
+	if i := strings.IndexAny(resource, "/:"); i >= 0 {
+		resource = resource[:i]
+	}
+	for _, f := range filters {
+		if f == service || f == service+":"+resource {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *ResourceGroupsTagging) GetResourcesWithContext(ctx aws.Context, in *resourcegroupstaggingapi.GetResourcesInput, _ ...request.Option) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return t.GetResources(in)
+}
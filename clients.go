@@ -0,0 +1,107 @@
+package chanute
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/aws/aws-sdk-go/service/redshift"
+	"github.com/aws/aws-sdk-go/service/redshift/redshiftiface"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/aws/aws-sdk-go/service/support/supportiface"
+)
+
+// Clients are the AWS APIs used to build a report.
+// Any of them can be swapped for a fake, see the chanutetest package.
+type Clients struct {
+	// Region is the region the clients were created for
+	Region string
+	// Partition is the ARN partition, such as aws-us-gov or aws-cn. It's found from Region when empty.
+	Partition string
+
+	Support  supportiface.SupportAPI
+	EC2      ec2iface.EC2API
+	ELBv2    elbv2iface.ELBV2API
+	RDS      rdsiface.RDSAPI
+	Redshift redshiftiface.RedshiftAPI
+	STS      stsiface.STSAPI
+	IAM      iamiface.IAMAPI
+	S3       s3iface.S3API
+	// ELB is the classic load balancer API
+	ELB         elbiface.ELBAPI
+	AutoScaling autoscalingiface.AutoScalingAPI
+	// ResourceGroupsTagging is only used with WithResourceGroupsTaggingAPI
+	ResourceGroupsTagging resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+
+	// NewRegion makes clients for another region, to look up tags of resources outside Region.
+	// Without it, resources in every region are looked up with these clients.
+	NewRegion func(region string) *Clients
+}
+
+// InRegion returns clients for region, or c if it's already in region or has no NewRegion
+func (c *Clients) InRegion(region string) *Clients {
+	region = regionOf(region)
+	if region == "" || region == c.Region || c.NewRegion == nil {
+		return c
+	}
+	return c.NewRegion(region)
+}
+
+// NewClients creates every client used by chanute from a single session.
+// Clients for other regions are copies of the session, made once per region.
+func NewClients(sess *session.Session) *Clients {
+	c := newClients(sess)
+
+	var mu sync.Mutex
+	regions := map[string]*Clients{c.Region: c}
+	c.NewRegion = func(region string) *Clients {
+		mu.Lock()
+		defer mu.Unlock()
+		if rc, ok := regions[region]; ok {
+			return rc
+		}
+		rc := newClients(sess.Copy(&aws.Config{Region: aws.String(region)}))
+		rc.NewRegion = c.NewRegion
+		regions[region] = rc
+		return rc
+	}
+	return c
+}
+
+func newClients(sess *session.Session) *Clients {
+	return &Clients{
+		Region:    aws.StringValue(sess.Config.Region),
+		Partition: sessionPartition(sess),
+
+		Support:     support.New(sess),
+		EC2:         ec2.New(sess),
+		ELBv2:       elbv2.New(sess),
+		RDS:         rds.New(sess),
+		Redshift:    redshift.New(sess),
+		STS:         sts.New(sess),
+		IAM:         iam.New(sess),
+		S3:          s3.New(sess),
+		ELB:         elb.New(sess),
+		AutoScaling: autoscaling.New(sess),
+
+		ResourceGroupsTagging: resourcegroupstaggingapi.New(sess),
+	}
+}
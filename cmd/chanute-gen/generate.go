@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/support"
+)
+
+type field struct {
+	Name   string
+	Column string
+	Type   string
+	Format string
+}
+
+func (f *field) Tag() string {
+	tag := f.Column
+	if f.Format != "" {
+		tag += "," + f.Format
+	}
+	return "`ta:" + strconv.Quote(tag) + "`"
+}
+
+// Cell is the expression used to render the field in a table
+func (f *field) Cell() string {
+	switch f.Format {
+	case "dollars":
+		return "PrintDollars(res." + f.Name + ")"
+	}
+	return "res." + f.Name
+}
+
+// tagKinds are the TagKind constants for columns that identify a resource the way its tags are looked up
+var tagKinds = map[string]string{
+	"instance id":             "TagKindEC2Instance",
+	"volume id":               "TagKindEBSVolume",
+	"security group id":       "TagKindSecurityGroup",
+	"reserved instance id":    "TagKindReservedInstances",
+	"db instance":             "TagKindRDSInstance",
+	"db instance name":        "TagKindRDSInstance",
+	"cluster":                 "TagKindRedshiftCluster",
+	"load balancer name":      "TagKindClassicLoadBalancer",
+	"auto scaling group name": "TagKindAutoScalingGroup",
+	"bucket name":             "TagKindS3Bucket",
+	"iam user":                "TagKindIAMUser",
+	"user name":               "TagKindIAMUser",
+}
+
+// regionColumns hold a region or an availability zone
+var regionColumns = map[string]bool{
+	"region":            true,
+	"region/az":         true,
+	"zone":              true,
+	"availability zone": true,
+}
+
+func generate(ch *support.TrustedAdvisorCheckDescription) ([]byte, error) {
+	name := aws.StringValue(ch.Name)
+	structName := identifier(name)
+
+	var fields []*field
+	var savings, region, id, resourceName, tagKind, tagID string
+	seen := map[string]int{}
+	for _, md := range ch.Metadata {
+		column := aws.StringValue(md)
+		f := &field{Name: identifier(column), Column: column, Type: "string"}
+		if f.Name == "" {
+			f.Name = "Column"
+		}
+		if n := seen[f.Name]; n > 0 {
+			f.Name += strconv.Itoa(n + 1)
+		}
+		seen[f.Name]++
+
+		lower := strings.ToLower(column)
+		if strings.Contains(lower, "savings") || strings.Contains(lower, "cost") {
+			f.Type = "int"
+			f.Format = "dollars"
+			if savings == "" && strings.Contains(lower, "savings") {
+				savings = f.Name
+			}
+		}
+		switch {
+		case regionColumns[lower] && region == "":
+			region = f.Name
+		case (lower == "id" || strings.HasSuffix(lower, " id")) && id == "":
+			id = f.Name
+		case strings.HasSuffix(lower, " name") && lower != "region name" && resourceName == "":
+			resourceName = f.Name
+		}
+		if kind, ok := tagKinds[lower]; ok && tagKind == "" {
+			tagKind, tagID = kind, f.Name
+		}
+		fields = append(fields, f)
+	}
+
+	r, n := utf8.DecodeRuneInString(structName)
+	buf := &bytes.Buffer{}
+	err := reportTemplate.Execute(buf, map[string]interface{}{
+		"Check":      name,
+		"Category":   aws.StringValue(ch.Category),
+		"StructName": structName,
+		"FuncName":   string(unicode.ToLower(r)) + structName[n:],
+		"Fields":     fields,
+		"Savings":    savings,
+		"Region":     region,
+		"ID":         id,
+		"Name":       resourceName,
+		"TagKind":    tagKind,
+		"TagID":      tagID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// identifier keeps letters and digits, and capitalizes each word, the same way the Check constants are named
+func identifier(s string) string {
+	var o strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		r, n := utf8.DecodeRuneInString(word)
+		o.WriteRune(unicode.ToUpper(r))
+		o.WriteString(word[n:])
+	}
+	id := o.String()
+	if id != "" && unicode.IsDigit([]rune(id)[0]) {
+		id = "N" + id
+	}
+	return id
+}
+
+func fileName(check string) string {
+	var words []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(check), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words = append(words, word)
+	}
+	return "report_" + strings.Join(words, "_") + ".go"
+}
+
+var reportTemplate = template.Must(template.New("").Parse(`package chanute
+
+import (
+	"context"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+{{- if .TagKind}}
+	"github.com/richardwilkes/toolbox/errs"
+{{- end}}
+)
+
+// {{.StructName}}Report is generated from the "{{.Check}}" ({{.Category}}) check
+type {{.StructName}}Report struct {
+	Resources []*{{.StructName}}
+	// Suppressed is only set when using WithSuppressedResources
+	Suppressed []*{{.StructName}}
+}
+
+var {{.FuncName}}Headers = []string{ {{- range .Fields}}{{printf "%q" .Column}}, {{end -}} }
+
+func (r *{{.StructName}}Report) AsciiReport() string {
+	var suppressed [][]string
+	for _, res := range r.Suppressed {
+		suppressed = append(suppressed, res.row())
+	}
+	return withSuppressed(r.asciiReport(), "{{.Check}}", {{.FuncName}}Headers, suppressed)
+}
+
+func (r *{{.StructName}}Report) asciiReport() string {
+	if len(r.Resources) == 0 {
+		return "{{.Check}}: No issues"
+	}
+	o := &strings.Builder{}
+	o.WriteString("{{.Check}}\n")
+
+	w := tablewriter.NewWriter(o)
+	w.SetHeader({{.FuncName}}Headers)
+	for _, res := range r.Resources {
+		w.Append(res.row())
+	}
+	w.Render()
+	return o.String()
+}
+
+func (r *{{.StructName}}Report) AggregateRows(a Aggregator) []*AggregateRow {
+	var o []*AggregateRow
+	for _, res := range r.Resources {
+		o = append(o, res.AggregateRow(a))
+	}
+	return o
+}
+
+type {{.StructName}} struct {
+	FlaggedResource
+{{range .Fields}}
+	{{.Name}} {{.Type}} {{.Tag}}
+{{- end}}
+
+	Tags map[string]string
+}
+
+func (res *{{.StructName}}) row() []string {
+	return []string{ {{- range .Fields}}{{.Cell}}, {{end -}} }
+}
+
+func (res *{{.StructName}}) AggregateRow(a Aggregator) *AggregateRow {
+	return &AggregateRow{
+		Service: "{{.StructName}}",
+{{- if .Region}}
+		Region: regionOf(res.{{.Region}}),
+{{- end}}
+{{- if .ID}}
+		ID: res.{{.ID}},
+{{- end}}
+{{- if .Name}}
+		Name: res.{{.Name}},
+{{- end}}
+		Key: a(aggregationTags(res.Tags, {{if .Name}}res.{{.Name}}{{else}}""{{end}}, {{if .ID}}res.{{.ID}}{{else}}""{{end}})),
+{{- if .Savings}}
+		MonthlySavings: res.{{.Savings}},
+{{- end}}
+	}
+}
+
+func {{.FuncName}}(ctx context.Context, config *Config, c *Clients, checks []*TrustedAdvisorCheck) (*{{.StructName}}Report, error) {
+	r := &{{.StructName}}Report{}
+	err := decodeReportResources(config, checks, &r.Resources, &r.Suppressed)
+{{- if .TagKind}}
+
+	if config.TagProvider != nil && len(r.Resources) > 0 {
+		ids := regionalIDs{}
+		for _, res := range r.Resources {
+			ids.add({{if .Region}}res.{{.Region}}{{else}}""{{end}}, res.{{.TagID}})
+		}
+		tags, tagErr := tagsByRegion(ctx, c, config, {{.TagKind}}, ids)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, res := range r.Resources {
+			res.Tags = tags.Get({{if .Region}}res.{{.Region}}{{else}}""{{end}}, res.{{.TagID}})
+		}
+	}
+{{- else}}
+	// TODO: look up Tags with tagsByRegion, the resource's TagKind couldn't be worked out from its columns
+{{- end}}
+	return r, err
+}
+`))
@@ -0,0 +1,94 @@
+// chanute-gen writes a typed report for Trusted Advisor checks that chanute doesn't handle yet.
+//
+// Check metadata is read from Trusted Advisor, or from a directory recorded with chanute.RecordClients:
+//
+//	chanute-gen -check "Amazon EBS Snapshots" -out .
+//	chanute-gen -replay fixtures/2020-03-01 -check "Amazon EBS Snapshots,Amazon RDS Backups"
+//
+// Each check gets a report_<check>.go with a resource struct, a builder, AsciiReport and AggregateRows.
+// Region, ID and name columns fill in AggregateRow, and tags are looked up when a column is an ID or name chanute has a TagKind for.
+// The builder still needs to be called from the report for the check's category.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/sheeley/chanute"
+)
+
+func main() {
+	checks := flag.String("check", "", "comma separated names of the checks to generate")
+	replay := flag.String("replay", "", "directory of recorded AWS responses to read check metadata from, instead of calling AWS")
+	out := flag.String("out", ".", "directory to write the generated files to")
+	force := flag.Bool("force", false, "overwrite existing files")
+	flag.Parse()
+
+	if *checks == "" {
+		fmt.Fprintln(os.Stderr, "-check is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(strings.Split(*checks, ","), *replay, *out, *force); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(names []string, replay, out string, force bool) error {
+	var clients *chanute.Clients
+	var err error
+	if replay != "" {
+		clients, err = chanute.ReplayClients(replay)
+		if err != nil {
+			return err
+		}
+	} else {
+		sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+		clients = chanute.NewClients(sess)
+	}
+
+	o, err := clients.Support.DescribeTrustedAdvisorChecksWithContext(context.Background(), &support.DescribeTrustedAdvisorChecksInput{Language: aws.String("en")})
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*support.TrustedAdvisorCheckDescription, len(o.Checks))
+	for _, ch := range o.Checks {
+		byName[aws.StringValue(ch.Name)] = ch
+	}
+
+	var checks []*support.TrustedAdvisorCheckDescription
+	for _, name := range names {
+		ch, ok := byName[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("%q is not a Trusted Advisor check", strings.TrimSpace(name))
+		}
+		checks = append(checks, ch)
+	}
+
+	for _, ch := range checks {
+		src, err := generate(ch)
+		if err != nil {
+			return err
+		}
+
+		path := filepath.Join(out, fileName(aws.StringValue(ch.Name)))
+		if _, err = os.Stat(path); err == nil && !force {
+			return fmt.Errorf("%s already exists, use -force to overwrite it", path)
+		}
+		if err = ioutil.WriteFile(path, src, 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "wrote %s\n", path)
+	}
+	return nil
+}
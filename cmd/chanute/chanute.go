@@ -1,50 +1,74 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"strings"
+	"io"
+	"os"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/sheeley/chanute"
 )
 
-func aggregator(tags map[string]string) string {
-	for k, v := range tags {
-		tags[strings.ToLower(k)] = strings.ToLower(v)
-	}
+// aggregator is the team tag, or the team in the organization tag
+var aggregator = chanute.FirstOf(
+	chanute.NormalizedTag("team"),
+	chanute.ParsedKV("organization", ":::", "=", "team"),
+)
+
+func main() {
+	record := flag.String("record", "", "directory to record AWS responses to")
+	replay := flag.String("replay", "", "directory of recorded AWS responses to build the report from, instead of calling AWS")
+	language := flag.String("language", "en", "language for Trusted Advisor check names and descriptions, such as ja")
+	taggingAPI := flag.Bool("tagging-api", false, "look up tags with the Resource Groups Tagging API, one call per region")
+	tagFile := flag.String("tags", "", "JSON or CSV file of tags by resource ID, which take precedence over tags in AWS")
+	rulesFile := flag.String("rules", "", "JSON or YAML file of ownership rules to aggregate by, instead of the team tag")
+	explain := flag.Bool("explain-rules", false, `with -rules, print which rule matched each resource to stderr, once per resource, as <account> <resource name> matched <rule> as "<key>"`)
+	flag.Parse()
 
-	if team, ok := tags["team"]; ok {
-		team = strings.TrimSpace(team)
-		if team != "" {
-			return team
+	var clients *chanute.Clients
+	var err error
+	if *replay != "" {
+		clients, err = chanute.ReplayClients(*replay)
+	} else {
+		sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
+		clients = chanute.NewClients(sess)
+		if *record != "" {
+			clients, err = chanute.RecordClients(clients, *record)
 		}
 	}
+	if err != nil {
+		panic(err)
+	}
 
-	if o, ok := tags["organization"]; ok {
-		spl := strings.Split(strings.ToLower(o), ":::")
-
-		for _, s := range spl {
-			kvSpl := strings.Split(s, "=")
-			if len(kvSpl) != 2 {
-				continue
-			}
-			if kvSpl[0] == "team" {
-				return kvSpl[1]
-			}
+	agg := aggregator
+	if *rulesFile != "" {
+		var explainLog io.Writer
+		if *explain {
+			explainLog = os.Stderr
+		}
+		agg, err = chanute.AggregatorFromFile(*rulesFile, explainLog)
+		if err != nil {
+			panic(err)
 		}
 	}
 
-	return ""
-	// return "No Team Tag"
-}
-
-func main() {
-	sess := session.Must(session.NewSession(&aws.Config{Region: aws.String("us-east-1")}))
-	r, err := chanute.GenerateReport(sess, chanute.WithCustomTagAggregator(aggregator),
+	options := []chanute.Option{chanute.WithCustomTagAggregator(agg),
 		chanute.WithoutResourceDetails(),
 		chanute.WithServiceLimitChecks(),
-	)
+		chanute.WithLanguage(*language),
+	}
+	var provider chanute.TagProvider = &chanute.AWSTagProvider{TaggingAPI: *taggingAPI}
+	if *tagFile != "" {
+		static, err := chanute.StaticTagProviderFromFile(*tagFile)
+		if err != nil {
+			panic(err)
+		}
+		provider = chanute.ChainTagProviders(static, provider)
+	}
+	options = append(options, chanute.WithTagProvider(provider))
+	r, err := chanute.GenerateClientReport(clients, options...)
 	// chanute.WithChecks(
 	// 	chanute.CheckEBS,
 	// 	chanute.CheckEC2,
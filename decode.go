@@ -0,0 +1,292 @@
+package chanute
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// DecodeResources fills dst, a pointer to a slice of struct pointers, with one element per flagged resource in checks.
+//
+// Fields are mapped to Trusted Advisor's English metadata columns, ignoring case, with a ta tag and an optional format:
+//
+//	ID      string `ta:"Instance ID"`
+//	Savings int    `ta:"Estimated Monthly Savings,dollars"`
+//	Days    int    `ta:"Number of Days Low Utilization,days"`
+//	MultiAZ bool   `ta:"Multi-AZ"`
+//
+// Formats are dollars ("$1,234.56" is 1234), days ("14 days" is 14, "14+" is 15) and percent ("12.5%" is 12.5).
+// Without a format, string, int, float64 and bool (Yes/No) fields are parsed as is, and empty values are left as zero.
+//
+// A struct that embeds FlaggedResource also gets each resource's status and whether it is suppressed.
+//
+// Problems are returned as DecodeErrors.
+// Every tagged column must be in the check's metadata, otherwise there is a *MissingColumnError and nothing is decoded for that check.
+// Values that can't be parsed are a *ParseError, but the rest of the resource is still decoded.
+func DecodeResources(checks []*TrustedAdvisorCheck, dst interface{}) error {
+	slice := reflect.ValueOf(dst)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return errs.Newf("dst must be a pointer to a slice of struct pointers, not %T", dst)
+	}
+	slice = slice.Elem()
+	ptrType := slice.Type().Elem()
+	if ptrType.Kind() != reflect.Ptr || ptrType.Elem().Kind() != reflect.Struct {
+		return errs.Newf("dst must be a pointer to a slice of struct pointers, not %T", dst)
+	}
+
+	fields, err := taFields(ptrType.Elem())
+	if err != nil {
+		return err
+	}
+	flaggedIdx := flaggedResourceIndex(ptrType.Elem())
+
+	var decodeErrs DecodeErrors
+	for _, check := range checks {
+		columns := columnIndex(check)
+
+		var missing bool
+		for _, f := range fields {
+			if _, ok := columns[strings.ToLower(f.column)]; !ok {
+				decodeErrs = append(decodeErrs, &MissingColumnError{Check: check.Name, Column: f.column})
+				missing = true
+			}
+		}
+		if missing || check.Result == nil {
+			continue
+		}
+
+		for _, res := range check.Result.FlaggedResources {
+			v := reflect.New(ptrType.Elem())
+			if flaggedIdx != -1 {
+				v.Elem().Field(flaggedIdx).Set(reflect.ValueOf(FlaggedResource{
+					Status:       aws.StringValue(res.Status),
+					IsSuppressed: aws.BoolValue(res.IsSuppressed),
+				}))
+			}
+			for _, f := range fields {
+				idx := columns[strings.ToLower(f.column)]
+				if idx >= len(res.Metadata) {
+					continue
+				}
+				raw := aws.StringValue(res.Metadata[idx])
+				if err := f.set(v.Elem().Field(f.index), raw); err != nil {
+					decodeErrs = append(decodeErrs, &ParseError{Check: check.Name, Column: f.column, Value: raw, Err: err})
+				}
+			}
+			slice.Set(reflect.Append(slice, v))
+		}
+	}
+
+	if len(decodeErrs) == 0 {
+		return nil
+	}
+	return decodeErrs
+}
+
+// FlaggedResource is Trusted Advisor's state for a flagged resource, filled by DecodeResources when it is embedded.
+// Checks with their own Status column, like the security checks and service limits, hide Status with that column,
+// Trusted Advisor's status is still in FlaggedResource.Status.
+type FlaggedResource struct {
+	// Status is ok, warning or error
+	Status string
+	// IsSuppressed is set for resources that have been excluded in the Trusted Advisor console
+	IsSuppressed bool
+}
+
+var flaggedResourceType = reflect.TypeOf(FlaggedResource{})
+
+func flaggedResourceIndex(t reflect.Type) int {
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); sf.Anonymous && sf.Type == flaggedResourceType {
+			return i
+		}
+	}
+	return -1
+}
+
+// decodeReportResources is DecodeResources for the reports.
+// Suppressed resources are moved from dst to suppressed when config.IncludeSuppressed is set, otherwise they're dropped.
+func decodeReportResources(config *Config, checks []*TrustedAdvisorCheck, dst, suppressed interface{}) error {
+	err := DecodeResources(checks, dst)
+	if _, ok := err.(DecodeErrors); err != nil && !ok {
+		return err
+	}
+
+	all := reflect.ValueOf(dst).Elem()
+	sup := reflect.ValueOf(suppressed).Elem()
+	idx := flaggedResourceIndex(all.Type().Elem().Elem())
+	if idx == -1 {
+		return err
+	}
+
+	kept := reflect.MakeSlice(all.Type(), 0, all.Len())
+	for i := 0; i < all.Len(); i++ {
+		v := all.Index(i)
+		if !v.Elem().Field(idx).Interface().(FlaggedResource).IsSuppressed {
+			kept = reflect.Append(kept, v)
+			continue
+		}
+		if config.IncludeSuppressed {
+			sup.Set(reflect.Append(sup, v))
+		}
+	}
+	all.Set(kept)
+	return err
+}
+
+// DecodeErrors are every *MissingColumnError and *ParseError found by DecodeResources
+type DecodeErrors []error
+
+func (e DecodeErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// MissingColumnError is returned when a tagged column isn't in a check's metadata
+type MissingColumnError struct {
+	Check  string
+	Column string
+}
+
+func (e *MissingColumnError) Error() string {
+	return fmt.Sprintf("%s: no %q column", e.Check, e.Column)
+}
+
+// ParseError is returned when a value can't be parsed into its field
+type ParseError struct {
+	Check  string
+	Column string
+	Value  string
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: can't parse %q in %q: %s", e.Check, e.Value, e.Column, e.Err)
+}
+
+// columnIndex maps each lowercased English metadata column name to its position, which is the same in every language.
+// Trusted Advisor hasn't capitalized column names consistently, so they are matched ignoring case.
+func columnIndex(check *TrustedAdvisorCheck) map[string]int {
+	columns := map[string]int{}
+	desc := check.English
+	if desc == nil {
+		desc = check.Check
+	}
+	if desc == nil {
+		return columns
+	}
+	for idx, md := range desc.Metadata {
+		columns[strings.ToLower(aws.StringValue(md))] = idx
+	}
+	return columns
+}
+
+type taField struct {
+	index  int
+	column string
+	format string
+	kind   reflect.Kind
+}
+
+func taFields(t reflect.Type) ([]*taField, error) {
+	var fields []*taField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("ta")
+		if !ok || tag == "-" {
+			continue
+		}
+		spl := strings.SplitN(tag, ",", 2)
+		f := &taField{index: i, column: spl[0], kind: sf.Type.Kind()}
+		if len(spl) == 2 {
+			f.format = spl[1]
+		}
+
+		switch f.format {
+		case "":
+		case "dollars", "days":
+			if f.kind != reflect.Int {
+				return nil, errs.Newf("%s.%s: %s needs an int field", t.Name(), sf.Name, f.format)
+			}
+		case "percent":
+			if f.kind != reflect.Float64 {
+				return nil, errs.Newf("%s.%s: percent needs a float64 field", t.Name(), sf.Name)
+			}
+		default:
+			return nil, errs.Newf("%s.%s: unknown format %q", t.Name(), sf.Name, f.format)
+		}
+
+		switch f.kind {
+		case reflect.String, reflect.Int, reflect.Float64, reflect.Bool:
+		default:
+			return nil, errs.Newf("%s.%s: unsupported type %s", t.Name(), sf.Name, sf.Type)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func (f *taField) set(v reflect.Value, raw string) error {
+	if f.kind == reflect.String {
+		v.SetString(raw)
+		return nil
+	}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+
+	switch f.format {
+	case "dollars":
+		i, err := parseDollars(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(i))
+		return nil
+	case "days":
+		i, err := parseDays(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(i))
+		return nil
+	case "percent":
+		fl, err := parsePercent(raw)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(fl)
+		return nil
+	}
+
+	switch f.kind {
+	case reflect.Int:
+		i, err := strconv.Atoi(strings.ReplaceAll(raw, ",", ""))
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(i))
+	case reflect.Float64:
+		fl, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", ""), 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(fl)
+	case reflect.Bool:
+		b, err := parseYesNo(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package chanute
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/support"
+)
+
+type decodeTestResource struct {
+	FlaggedResource
+
+	ID       string  `ta:"Instance ID"`
+	Savings  int     `ta:"Estimated Monthly Savings,dollars"`
+	Days     int     `ta:"Number of Days Low Utilization,days"`
+	CPU      float64 `ta:"CPU Utilization,percent"`
+	Count    int     `ta:"Instance Count"`
+	MultiAZ  bool    `ta:"Multi-AZ"`
+	Ignored  string  `ta:"-"`
+	Untagged string
+}
+
+var decodeTestColumns = []string{"Instance ID", "Estimated Monthly Savings", "Number of Days Low Utilization", "CPU Utilization", "Instance Count", "Multi-AZ"}
+
+// decodeTestCheck has a flagged resource per row, rows starting with "suppressed-" are suppressed
+func decodeTestCheck(columns []string, rows ...[]string) *TrustedAdvisorCheck {
+	ch := &TrustedAdvisorCheck{
+		Name:   "Test Check",
+		Check:  &support.TrustedAdvisorCheckDescription{Metadata: aws.StringSlice(columns)},
+		Result: &support.TrustedAdvisorCheckResult{},
+	}
+	for _, row := range rows {
+		ch.Result.FlaggedResources = append(ch.Result.FlaggedResources, &support.TrustedAdvisorResourceDetail{
+			Status:       aws.String("warning"),
+			IsSuppressed: aws.Bool(len(row) > 0 && strings.HasPrefix(row[0], "suppressed-")),
+			Metadata:     aws.StringSlice(row),
+		})
+	}
+	return ch
+}
+
+func TestDecodeResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		row     []string
+		want    *decodeTestResource
+		errs    int
+	}{
+		{
+			name:    "formats",
+			columns: decodeTestColumns,
+			row:     []string{"i-1", "$1,234.56", "14+", "12.5%", "1,024", "Yes"},
+			want:    &decodeTestResource{ID: "i-1", Savings: 1234, Days: 15, CPU: 12.5, Count: 1024, MultiAZ: true},
+		},
+		{
+			name:    "days and no",
+			columns: decodeTestColumns,
+			row:     []string{"i-2", "$0", "3 days", "0%", "2", "No"},
+			want:    &decodeTestResource{ID: "i-2", Days: 3, Count: 2},
+		},
+		{
+			name:    "empty values are zero",
+			columns: decodeTestColumns,
+			row:     []string{"i-3", "", " ", "", "", ""},
+			want:    &decodeTestResource{ID: "i-3"},
+		},
+		{
+			name:    "columns are matched by name, ignoring case",
+			columns: []string{"multi-az", "Instance Count", "CPU UTILIZATION", "Number of Days Low Utilization", "Estimated Monthly Savings", "Instance ID"},
+			row:     []string{"Yes", "4", "50%", "7 days", "$10", "i-4"},
+			want:    &decodeTestResource{ID: "i-4", Savings: 10, Days: 7, CPU: 50, Count: 4, MultiAZ: true},
+		},
+		{
+			name:    "unparseable values are left as zero",
+			columns: decodeTestColumns,
+			row:     []string{"i-5", "lots", "7 days", "half", "2", "maybe"},
+			want:    &decodeTestResource{ID: "i-5", Days: 7, Count: 2},
+			errs:    3,
+		},
+		{
+			name:    "short rows",
+			columns: decodeTestColumns,
+			row:     []string{"i-6", "$5"},
+			want:    &decodeTestResource{ID: "i-6", Savings: 5},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []*decodeTestResource
+			err := DecodeResources([]*TrustedAdvisorCheck{decodeTestCheck(test.columns, test.row)}, &got)
+
+			decodeErrs, _ := err.(DecodeErrors)
+			if len(decodeErrs) != test.errs || (err != nil && decodeErrs == nil) {
+				t.Fatalf("expected %d errors, got %v", test.errs, err)
+			}
+			for _, e := range decodeErrs {
+				if _, ok := e.(*ParseError); !ok {
+					t.Errorf("expected a *ParseError, got %T", e)
+				}
+			}
+
+			test.want.FlaggedResource = FlaggedResource{Status: "warning"}
+			if len(got) != 1 || !reflect.DeepEqual(got[0], test.want) {
+				t.Errorf("got %+v, expected %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeResourcesMissingColumn(t *testing.T) {
+	complete := decodeTestCheck(decodeTestColumns, []string{"i-1", "$1", "1 days", "1%", "1", "No"})
+	missing := decodeTestCheck(decodeTestColumns[1:], []string{"$1", "1 days", "1%", "1", "No"})
+
+	var got []*decodeTestResource
+	err := DecodeResources([]*TrustedAdvisorCheck{missing, complete}, &got)
+	decodeErrs, ok := err.(DecodeErrors)
+	if !ok || len(decodeErrs) != 1 {
+		t.Fatalf("expected a decode error, got %v", err)
+	}
+	mce, ok := decodeErrs[0].(*MissingColumnError)
+	if !ok || mce.Check != "Test Check" || mce.Column != "Instance ID" {
+		t.Errorf("expected a missing Instance ID column, got %#v", decodeErrs[0])
+	}
+	// the check with every column is still decoded
+	if len(got) != 1 || got[0].ID != "i-1" {
+		t.Errorf("expected i-1 to be decoded, got %+v", got)
+	}
+}
+
+func TestDecodeResourcesInvalidDestination(t *testing.T) {
+	type badFormat struct {
+		Savings string `ta:"Estimated Monthly Savings,dollars"`
+	}
+	type unknownFormat struct {
+		Savings int `ta:"Estimated Monthly Savings,euros"`
+	}
+	checks := []*TrustedAdvisorCheck{decodeTestCheck(decodeTestColumns)}
+	for _, dst := range []interface{}{
+		[]*decodeTestResource{},
+		&[]decodeTestResource{},
+		&[]*badFormat{},
+		&[]*unknownFormat{},
+	} {
+		err := DecodeResources(checks, dst)
+		if _, ok := err.(DecodeErrors); err == nil || ok {
+			t.Errorf("%T: expected an error, got %v", dst, err)
+		}
+	}
+}
+
+func TestDecodeReportResourcesSuppressed(t *testing.T) {
+	check := decodeTestCheck(decodeTestColumns,
+		[]string{"i-1", "$1", "1 days", "1%", "1", "No"},
+		[]string{"suppressed-i-2", "$2", "2 days", "2%", "2", "No"},
+		[]string{"i-3", "$3", "3 days", "3%", "3", "No"},
+	)
+	ids := func(resources []*decodeTestResource) []string {
+		var o []string
+		for _, r := range resources {
+			o = append(o, r.ID)
+		}
+		return o
+	}
+
+	tests := []struct {
+		name              string
+		includeSuppressed bool
+		suppressed        []string
+	}{
+		{name: "dropped by default"},
+		{name: "included", includeSuppressed: true, suppressed: []string{"suppressed-i-2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var resources, suppressed []*decodeTestResource
+			err := decodeReportResources(&Config{IncludeSuppressed: test.includeSuppressed}, []*TrustedAdvisorCheck{check}, &resources, &suppressed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(resources); !reflect.DeepEqual(got, []string{"i-1", "i-3"}) {
+				t.Errorf("resources are %v", got)
+			}
+			if got := ids(suppressed); !reflect.DeepEqual(got, test.suppressed) {
+				t.Errorf("suppressed are %v, expected %v", got, test.suppressed)
+			}
+			for _, r := range suppressed {
+				if !r.IsSuppressed {
+					t.Errorf("%s isn't marked suppressed", r.ID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package chanute
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// Finding is a flagged resource from a check without savings, in a common shape for reporting
+type Finding struct {
+	Check  Check
+	Name   string
+	ID     string
+	Region string
+	Detail string
+	Tags   map[string]string
+}
+
+func (f *Finding) row() []string {
+	return []string{f.Name, f.ID, f.Region, f.Detail}
+}
+
+type FindingAggregate struct {
+	Key      string
+	Count    int
+	Findings []*Finding
+}
+
+var findingHeaders = []string{"Name", "ID", "Region", "Detail"}
+
+// aggregateFindings groups findings by the configured Aggregator, falling back to the name or ID of untagged resources
+func aggregateFindings(config *Config, findings []*Finding) []*FindingAggregate {
+	aggregated := map[string]*FindingAggregate{}
+	for _, f := range findings {
+		key := config.Aggregator(aggregationTags(f.Tags, f.Name, f.ID))
+		if key == "" {
+			key = f.Name
+			if key == "" {
+				key = f.ID
+			}
+		}
+		if _, ok := aggregated[key]; !ok {
+			aggregated[key] = &FindingAggregate{Key: key}
+		}
+		if !config.HideResourceDetails {
+			aggregated[key].Findings = append(aggregated[key].Findings, f)
+		}
+		aggregated[key].Count++
+	}
+
+	var o []*FindingAggregate
+	for _, agg := range aggregated {
+		o = append(o, agg)
+	}
+	sort.Slice(o, func(i, j int) bool {
+		if o[i].Count != o[j].Count {
+			return o[i].Count > o[j].Count
+		}
+		return o[i].Key < o[j].Key
+	})
+	return o
+}
+
+func sortFindings(findings []*Finding) {
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Check != b.Check {
+			return a.Check < b.Check
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+}
+
+// writeFindings writes a table per check in the given order, or a single table of the aggregates if there are any
+func writeFindings(o *strings.Builder, order []Check, findings []*Finding, aggregated []*FindingAggregate) {
+	if aggregated == nil {
+		byCheck := map[Check][][]string{}
+		for _, f := range findings {
+			byCheck[f.Check] = append(byCheck[f.Check], f.row())
+		}
+		for _, chk := range order {
+			if rows := byCheck[chk]; len(rows) > 0 {
+				o.WriteString(string(chk) + "\n")
+				Table(o, findingHeaders, rows)
+			}
+		}
+		return
+	}
+
+	w := tablewriter.NewWriter(o)
+	w.SetHeader([]string{"Name", "Check", "ID", "Region", "Detail"})
+	for _, agg := range aggregated {
+		w.Append([]string{agg.Key, strconv.Itoa(agg.Count) + " findings", "", "", ""})
+
+		if len(agg.Findings) > 0 {
+			for _, f := range agg.Findings {
+				w.Append([]string{f.Name, string(f.Check), f.ID, f.Region, f.Detail})
+			}
+			w.Append([]string{"", "", "", "", ""})
+		}
+	}
+	w.Render()
+}
+
+// withSuppressedFindings adds a table of suppressed findings after a report, if there are any
+func withSuppressedFindings(report, title string, suppressed []*Finding) string {
+	var rows [][]string
+	for _, f := range suppressed {
+		rows = append(rows, append([]string{string(f.Check)}, f.row()...))
+	}
+	return withSuppressed(report, title, append([]string{"Check"}, findingHeaders...), rows)
+}
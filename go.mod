@@ -8,4 +8,5 @@ require (
 	github.com/olekukonko/tablewriter v0.0.4
 	github.com/richardwilkes/toolbox v1.24.2
 	golang.org/x/text v0.3.0
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -1,34 +1,40 @@
 package chanute
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 )
 
-func parseAmount(s string) int {
+// parseDollars truncates to whole dollars
+func parseDollars(s string) (int, error) {
 	s = strings.ReplaceAll(s, "$", "")
+	s = strings.ReplaceAll(s, ",", "")
 	idx := strings.Index(s, ".")
 	if idx != -1 {
 		s = s[0:idx]
 	}
-	i, err := strconv.Atoi(s)
-	if err != nil {
-		fmt.Println(err)
-		return 0
-	}
-	return i
+	return strconv.Atoi(strings.TrimSpace(s))
 }
 
-func parseDays(s string) int {
+func parseDays(s string) (int, error) {
 	if s == "14+" {
-		return 15
+		return 15, nil
 	}
 	s = strings.TrimSpace(strings.ReplaceAll(s, "days", ""))
-	i, err := strconv.Atoi(s)
-	if err != nil {
-		fmt.Println(err)
-		return 0
+	return strconv.Atoi(s)
+}
+
+func parsePercent(s string) (float64, error) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "%", ""))
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseYesNo(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
 	}
-	return i
+	return strconv.ParseBool(s)
 }
@@ -0,0 +1,139 @@
+package chanute
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AggregateDimension is a field of AggregateRow that a summary can be pivoted by
+type AggregateDimension string
+
+const (
+	DimensionKey     AggregateDimension = "Key"
+	DimensionService AggregateDimension = "Service"
+	DimensionEnv     AggregateDimension = "Env"
+	DimensionRegion  AggregateDimension = "Region"
+)
+
+func (d AggregateDimension) value(row *AggregateRow) string {
+	switch d {
+	case DimensionKey:
+		return row.Key
+	case DimensionService:
+		return row.Service
+	case DimensionEnv:
+		return row.Env
+	case DimensionRegion:
+		return row.Region
+	}
+	return ""
+}
+
+// PivotGroup is the rows with the same Value of Dimension, within the group above it.
+// Groups are split by the next dimension, and sorted by savings, then value.
+// Rows are only set on groups of the last dimension.
+type PivotGroup struct {
+	Dimension      AggregateDimension
+	Value          string
+	Resources      int
+	MonthlySavings int
+	Groups         []*PivotGroup
+	Rows           []*AggregateRow
+}
+
+// Pivot is an AggregateSummary grouped by each of Dimensions in turn, Total is the grand total
+type Pivot struct {
+	Dimensions []AggregateDimension
+	Total      *PivotGroup
+}
+
+// Pivot groups and subtotals the summary's rows by dims, in order.
+// Without dims, it is only the grand total.
+func (s *AggregateSummary) Pivot(dims ...AggregateDimension) *Pivot {
+	total := &PivotGroup{}
+	pivotGroup(total, dims, s.allRows)
+	return &Pivot{Dimensions: dims, Total: total}
+}
+
+func pivotGroup(g *PivotGroup, dims []AggregateDimension, rows []*AggregateRow) {
+	for _, row := range rows {
+		g.Resources++
+		g.MonthlySavings += row.MonthlySavings
+	}
+	if len(dims) == 0 {
+		g.Rows = rows
+		return
+	}
+
+	byValue := map[string][]*AggregateRow{}
+	var values []string
+	for _, row := range rows {
+		v := dims[0].value(row)
+		if _, ok := byValue[v]; !ok {
+			values = append(values, v)
+		}
+		byValue[v] = append(byValue[v], row)
+	}
+	for _, v := range values {
+		child := &PivotGroup{Dimension: dims[0], Value: v}
+		pivotGroup(child, dims[1:], byValue[v])
+		g.Groups = append(g.Groups, child)
+	}
+	sort.Slice(g.Groups, func(i, j int) bool {
+		if g.Groups[i].MonthlySavings != g.Groups[j].MonthlySavings {
+			return g.Groups[i].MonthlySavings > g.Groups[j].MonthlySavings
+		}
+		return g.Groups[i].Value < g.Groups[j].Value
+	})
+}
+
+func (p *Pivot) Headers() []string {
+	var o []string
+	for _, d := range p.Dimensions {
+		o = append(o, string(d))
+	}
+	if len(o) == 0 {
+		// the column "Total" is in
+		o = append(o, "")
+	}
+	return append(o, "Resources", "Monthly Savings")
+}
+
+// Rows are a row per group, with subtotals before the groups inside them and the grand total last.
+// A subtotal leaves the columns of the dimensions it is split by empty, so empty values are shown as (none).
+func (p *Pivot) Rows() [][]string {
+	var o [][]string
+	var walk func(g *PivotGroup, values []string)
+	walk = func(g *PivotGroup, values []string) {
+		for _, child := range g.Groups {
+			value := child.Value
+			if value == "" {
+				value = "(none)"
+			}
+			childValues := append(append([]string{}, values...), value)
+			o = append(o, p.row(childValues, child))
+			walk(child, childValues)
+		}
+	}
+	walk(p.Total, nil)
+	return append(o, p.row([]string{"Total"}, p.Total))
+}
+
+// row has a column per dimension, or one for "Total" without dimensions.
+// Savings are plain numbers, like in SummaryRows.
+func (p *Pivot) row(values []string, g *PivotGroup) []string {
+	columns := len(p.Dimensions)
+	if columns == 0 {
+		columns = 1
+	}
+	row := make([]string, columns)
+	copy(row, values)
+	return append(row, strconv.Itoa(g.Resources), strconv.Itoa(g.MonthlySavings))
+}
+
+func (p *Pivot) AsciiReport() string {
+	o := &strings.Builder{}
+	Table(o, p.Headers(), p.Rows())
+	return o.String()
+}
@@ -0,0 +1,67 @@
+package chanute
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPivotRows(t *testing.T) {
+	s := &AggregateSummary{allRows: []*AggregateRow{
+		{Service: "EC2", Key: "data", Env: "prod", ID: "i-1", MonthlySavings: 10},
+		{Service: "RDS", Key: "data", Env: "dev", ID: "orders", MonthlySavings: 1200},
+		{Service: "EC2", Key: "web", Env: "prod", ID: "i-2", MonthlySavings: 30},
+		{Service: "EC2", Key: "", Env: "prod", ID: "i-3", MonthlySavings: 5},
+	}}
+
+	tests := []struct {
+		name    string
+		dims    []AggregateDimension
+		headers []string
+		rows    [][]string
+	}{
+		{
+			name:    "no dimensions",
+			headers: []string{"", "Resources", "Monthly Savings"},
+			rows:    [][]string{{"Total", "4", "1245"}},
+		},
+		{
+			name:    "one dimension",
+			dims:    []AggregateDimension{DimensionKey},
+			headers: []string{"Key", "Resources", "Monthly Savings"},
+			rows: [][]string{
+				{"data", "2", "1210"},
+				{"web", "1", "30"},
+				{"(none)", "1", "5"},
+				{"Total", "4", "1245"},
+			},
+		},
+		{
+			name:    "two dimensions",
+			dims:    []AggregateDimension{DimensionEnv, DimensionService},
+			headers: []string{"Env", "Service", "Resources", "Monthly Savings"},
+			rows: [][]string{
+				{"dev", "", "1", "1200"},
+				{"dev", "RDS", "1", "1200"},
+				{"prod", "", "3", "45"},
+				{"prod", "EC2", "3", "45"},
+				{"Total", "", "4", "1245"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := s.Pivot(test.dims...)
+			if got := p.Headers(); !reflect.DeepEqual(got, test.headers) {
+				t.Errorf("headers are %q, expected %q", got, test.headers)
+			}
+			if got := p.Rows(); !reflect.DeepEqual(got, test.rows) {
+				t.Errorf("rows are %q, expected %q", got, test.rows)
+			}
+			for _, row := range p.Rows() {
+				if len(row) != len(test.headers) {
+					t.Errorf("row %q doesn't have a column per header", row)
+				}
+			}
+		})
+	}
+}
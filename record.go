@@ -0,0 +1,243 @@
+package chanute
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// A fixture directory has a clients.json, and one file per API response in a directory per service:
+//
+//	<dir>/clients.json
+//	<dir>/ec2/DescribeInstances-<hash of the input>.json
+//	<dir>/<other region>/ec2/DescribeInstances-<hash of the input>.json
+//
+// Calls made again with the same input, like polling refresh statuses, are numbered from the second call,
+// DescribeTrustedAdvisorCheckRefreshStatuses-<hash of the input>-2.json, and replayed in the same order.
+// Errors are recorded too, so the not found handling in the tag lookups replays exactly.
+
+const fixtureManifest = "clients.json"
+
+type fixtureClients struct {
+	Region    string `json:"region"`
+	Partition string `json:"partition,omitempty"`
+}
+
+type fixture struct {
+	Output json.RawMessage `json:"output,omitempty"`
+	Error  *fixtureError   `json:"error,omitempty"`
+}
+
+type fixtureError struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// RecordClients wraps every client so its responses are written to dir, to be replayed later by ReplayClients
+func RecordClients(c *Clients, dir string) (*Clients, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	manifest, err := json.MarshalIndent(&fixtureClients{Region: c.Region, Partition: c.Partition}, "", "  ")
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, fixtureManifest), manifest, 0644); err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	return recordingClients(c, &fixtureCalls{}, dir, dir), nil
+}
+
+// recordingClients records to dir, and clients for other regions record to a directory per region in root
+func recordingClients(c *Clients, calls *fixtureCalls, root, dir string) *Clients {
+	rec := &recorder{dir: dir, calls: calls}
+	return &Clients{
+		Region:    c.Region,
+		Partition: c.Partition,
+
+		Support:     &recordingSupport{SupportAPI: c.Support, rec: rec},
+		EC2:         &recordingEC2{EC2API: c.EC2, rec: rec},
+		ELBv2:       &recordingELBv2{ELBV2API: c.ELBv2, rec: rec},
+		RDS:         &recordingRDS{RDSAPI: c.RDS, rec: rec},
+		Redshift:    &recordingRedshift{RedshiftAPI: c.Redshift, rec: rec},
+		STS:         &recordingSTS{STSAPI: c.STS, rec: rec},
+		IAM:         &recordingIAM{IAMAPI: c.IAM, rec: rec},
+		S3:          &recordingS3{S3API: c.S3, rec: rec},
+		ELB:         &recordingELB{ELBAPI: c.ELB, rec: rec},
+		AutoScaling: &recordingAutoScaling{AutoScalingAPI: c.AutoScaling, rec: rec},
+
+		ResourceGroupsTagging: &recordingResourceGroupsTagging{ResourceGroupsTaggingAPIAPI: c.ResourceGroupsTagging, rec: rec},
+
+		NewRegion: func(region string) *Clients {
+			return recordingClients(c.InRegion(region), calls, root, filepath.Join(root, region))
+		},
+	}
+}
+
+// ReplayClients returns clients that answer every call from the responses recorded in dir by RecordClients.
+// A call that wasn't recorded, or is made more times than it was, fails with ErrNotRecorded.
+// Refresh statuses are still polled at the refresh poll interval, WithRefreshPollInterval shortens it.
+func ReplayClients(dir string) (*Clients, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, fixtureManifest))
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	manifest := &fixtureClients{}
+	if err = json.Unmarshal(b, manifest); err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	return replayClients(manifest.Region, manifest.Partition, &fixtureCalls{}, dir, dir), nil
+}
+
+func replayClients(region, partition string, calls *fixtureCalls, root, dir string) *Clients {
+	rep := &replayer{dir: dir, calls: calls}
+	return &Clients{
+		Region:    region,
+		Partition: partition,
+
+		Support:     &replaySupport{rep: rep},
+		EC2:         &replayEC2{rep: rep},
+		ELBv2:       &replayELBv2{rep: rep},
+		RDS:         &replayRDS{rep: rep},
+		Redshift:    &replayRedshift{rep: rep},
+		STS:         &replaySTS{rep: rep},
+		IAM:         &replayIAM{rep: rep},
+		S3:          &replayS3{rep: rep},
+		ELB:         &replayELB{rep: rep},
+		AutoScaling: &replayAutoScaling{rep: rep},
+
+		ResourceGroupsTagging: &replayResourceGroupsTagging{rep: rep},
+
+		NewRegion: func(region string) *Clients {
+			return replayClients(region, partition, calls, root, filepath.Join(root, region))
+		},
+	}
+}
+
+// ErrNotRecorded is returned when replaying a call that isn't in the fixture directory
+var ErrNotRecorded = errors.New("call was not recorded")
+
+// fixturePath is the path of the call'th call with input, counting from 1
+func fixturePath(dir, service, operation string, input interface{}, call int) (string, error) {
+	b, err := json.Marshal(input)
+	if err != nil {
+		return "", errs.Wrap(err)
+	}
+	sum := sha1.Sum(b)
+	name := operation + "-" + hex.EncodeToString(sum[:8])
+	if call > 1 {
+		name += "-" + strconv.Itoa(call)
+	}
+	return filepath.Join(dir, service, name+".json"), nil
+}
+
+// fixtureCalls counts the calls made with each input, shared by the clients of every region
+type fixtureCalls struct {
+	mu sync.Mutex
+	n  map[string]int
+}
+
+// next is the number of this call with input
+func (c *fixtureCalls) next(dir, service, operation string, input interface{}) (int, error) {
+	path, err := fixturePath(dir, service, operation, input, 1)
+	if err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.n == nil {
+		c.n = map[string]int{}
+	}
+	c.n[path]++
+	return c.n[path], nil
+}
+
+type recorder struct {
+	dir   string
+	calls *fixtureCalls
+}
+
+// save writes the response and returns the call's error, or an error writing the fixture
+func (r *recorder) save(service, operation string, input, output interface{}, callErr error) error {
+	f := &fixture{}
+	if callErr != nil {
+		f.Error = &fixtureError{Message: callErr.Error()}
+		if ae, ok := callErr.(awserr.Error); ok {
+			f.Error.Code = ae.Code()
+			f.Error.Message = ae.Message()
+		}
+	} else {
+		out, err := json.Marshal(output)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		f.Output = out
+	}
+
+	call, err := r.calls.next(r.dir, service, operation, input)
+	if err != nil {
+		return err
+	}
+	path, err := fixturePath(r.dir, service, operation, input, call)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errs.Wrap(err)
+	}
+	if err = ioutil.WriteFile(path, b, 0644); err != nil {
+		return errs.Wrap(err)
+	}
+	return callErr
+}
+
+type replayer struct {
+	dir   string
+	calls *fixtureCalls
+}
+
+// load fills output with the recorded response, or returns the recorded error
+func (r *replayer) load(service, operation string, input, output interface{}) error {
+	call, err := r.calls.next(r.dir, service, operation, input)
+	if err != nil {
+		return err
+	}
+	path, err := fixturePath(r.dir, service, operation, input, call)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errs.NewWithCause(service+"."+operation+" "+filepath.Base(path), ErrNotRecorded)
+		}
+		return errs.Wrap(err)
+	}
+
+	f := &fixture{}
+	if err = json.Unmarshal(b, f); err != nil {
+		return errs.Wrap(err)
+	}
+	if f.Error != nil {
+		if f.Error.Code == "" {
+			return errors.New(f.Error.Message)
+		}
+		return awserr.New(f.Error.Code, f.Error.Message, nil)
+	}
+	return json.Unmarshal(f.Output, output)
+}
@@ -0,0 +1,387 @@
+package chanute
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elb/elbiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/aws/aws-sdk-go/service/redshift"
+	"github.com/aws/aws-sdk-go/service/redshift/redshiftiface"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/aws/aws-sdk-go/service/support/supportiface"
+)
+
+// The recording clients pass every call through and save the response, the replay clients only answer the calls chanute makes.
+
+type recordingSupport struct {
+	supportiface.SupportAPI
+	rec *recorder
+}
+
+func (c *recordingSupport) DescribeTrustedAdvisorChecksWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorChecksInput, opts ...request.Option) (*support.DescribeTrustedAdvisorChecksOutput, error) {
+	out, err := c.SupportAPI.DescribeTrustedAdvisorChecksWithContext(ctx, in, opts...)
+	return out, c.rec.save("support", "DescribeTrustedAdvisorChecks", in, out, err)
+}
+
+func (c *recordingSupport) DescribeTrustedAdvisorCheckResultWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckResultInput, opts ...request.Option) (*support.DescribeTrustedAdvisorCheckResultOutput, error) {
+	out, err := c.SupportAPI.DescribeTrustedAdvisorCheckResultWithContext(ctx, in, opts...)
+	return out, c.rec.save("support", "DescribeTrustedAdvisorCheckResult", in, out, err)
+}
+
+func (c *recordingSupport) DescribeTrustedAdvisorCheckSummariesWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckSummariesInput, opts ...request.Option) (*support.DescribeTrustedAdvisorCheckSummariesOutput, error) {
+	out, err := c.SupportAPI.DescribeTrustedAdvisorCheckSummariesWithContext(ctx, in, opts...)
+	return out, c.rec.save("support", "DescribeTrustedAdvisorCheckSummaries", in, out, err)
+}
+
+func (c *recordingSupport) DescribeTrustedAdvisorCheckRefreshStatusesWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckRefreshStatusesInput, opts ...request.Option) (*support.DescribeTrustedAdvisorCheckRefreshStatusesOutput, error) {
+	out, err := c.SupportAPI.DescribeTrustedAdvisorCheckRefreshStatusesWithContext(ctx, in, opts...)
+	return out, c.rec.save("support", "DescribeTrustedAdvisorCheckRefreshStatuses", in, out, err)
+}
+
+func (c *recordingSupport) RefreshTrustedAdvisorCheckWithContext(ctx aws.Context, in *support.RefreshTrustedAdvisorCheckInput, opts ...request.Option) (*support.RefreshTrustedAdvisorCheckOutput, error) {
+	out, err := c.SupportAPI.RefreshTrustedAdvisorCheckWithContext(ctx, in, opts...)
+	return out, c.rec.save("support", "RefreshTrustedAdvisorCheck", in, out, err)
+}
+
+type replaySupport struct {
+	supportiface.SupportAPI
+	rep *replayer
+}
+
+func (c *replaySupport) DescribeTrustedAdvisorChecksWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorChecksInput, _ ...request.Option) (*support.DescribeTrustedAdvisorChecksOutput, error) {
+	out := &support.DescribeTrustedAdvisorChecksOutput{}
+	if err := c.rep.load("support", "DescribeTrustedAdvisorChecks", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replaySupport) DescribeTrustedAdvisorCheckResultWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckResultInput, _ ...request.Option) (*support.DescribeTrustedAdvisorCheckResultOutput, error) {
+	out := &support.DescribeTrustedAdvisorCheckResultOutput{}
+	if err := c.rep.load("support", "DescribeTrustedAdvisorCheckResult", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replaySupport) DescribeTrustedAdvisorCheckSummariesWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckSummariesInput, _ ...request.Option) (*support.DescribeTrustedAdvisorCheckSummariesOutput, error) {
+	out := &support.DescribeTrustedAdvisorCheckSummariesOutput{}
+	if err := c.rep.load("support", "DescribeTrustedAdvisorCheckSummaries", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replaySupport) DescribeTrustedAdvisorCheckRefreshStatusesWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckRefreshStatusesInput, _ ...request.Option) (*support.DescribeTrustedAdvisorCheckRefreshStatusesOutput, error) {
+	out := &support.DescribeTrustedAdvisorCheckRefreshStatusesOutput{}
+	if err := c.rep.load("support", "DescribeTrustedAdvisorCheckRefreshStatuses", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replaySupport) RefreshTrustedAdvisorCheckWithContext(ctx aws.Context, in *support.RefreshTrustedAdvisorCheckInput, _ ...request.Option) (*support.RefreshTrustedAdvisorCheckOutput, error) {
+	out := &support.RefreshTrustedAdvisorCheckOutput{}
+	if err := c.rep.load("support", "RefreshTrustedAdvisorCheck", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type recordingEC2 struct {
+	ec2iface.EC2API
+	rec *recorder
+}
+
+func (c *recordingEC2) DescribeInstancesWithContext(ctx aws.Context, in *ec2.DescribeInstancesInput, opts ...request.Option) (*ec2.DescribeInstancesOutput, error) {
+	out, err := c.EC2API.DescribeInstancesWithContext(ctx, in, opts...)
+	return out, c.rec.save("ec2", "DescribeInstances", in, out, err)
+}
+
+func (c *recordingEC2) DescribeVolumesWithContext(ctx aws.Context, in *ec2.DescribeVolumesInput, opts ...request.Option) (*ec2.DescribeVolumesOutput, error) {
+	out, err := c.EC2API.DescribeVolumesWithContext(ctx, in, opts...)
+	return out, c.rec.save("ec2", "DescribeVolumes", in, out, err)
+}
+
+func (c *recordingEC2) DescribeSecurityGroupsWithContext(ctx aws.Context, in *ec2.DescribeSecurityGroupsInput, opts ...request.Option) (*ec2.DescribeSecurityGroupsOutput, error) {
+	out, err := c.EC2API.DescribeSecurityGroupsWithContext(ctx, in, opts...)
+	return out, c.rec.save("ec2", "DescribeSecurityGroups", in, out, err)
+}
+
+func (c *recordingEC2) DescribeReservedInstancesWithContext(ctx aws.Context, in *ec2.DescribeReservedInstancesInput, opts ...request.Option) (*ec2.DescribeReservedInstancesOutput, error) {
+	out, err := c.EC2API.DescribeReservedInstancesWithContext(ctx, in, opts...)
+	return out, c.rec.save("ec2", "DescribeReservedInstances", in, out, err)
+}
+
+type replayEC2 struct {
+	ec2iface.EC2API
+	rep *replayer
+}
+
+func (c *replayEC2) DescribeInstancesWithContext(ctx aws.Context, in *ec2.DescribeInstancesInput, _ ...request.Option) (*ec2.DescribeInstancesOutput, error) {
+	out := &ec2.DescribeInstancesOutput{}
+	if err := c.rep.load("ec2", "DescribeInstances", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replayEC2) DescribeVolumesWithContext(ctx aws.Context, in *ec2.DescribeVolumesInput, _ ...request.Option) (*ec2.DescribeVolumesOutput, error) {
+	out := &ec2.DescribeVolumesOutput{}
+	if err := c.rep.load("ec2", "DescribeVolumes", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replayEC2) DescribeSecurityGroupsWithContext(ctx aws.Context, in *ec2.DescribeSecurityGroupsInput, _ ...request.Option) (*ec2.DescribeSecurityGroupsOutput, error) {
+	out := &ec2.DescribeSecurityGroupsOutput{}
+	if err := c.rep.load("ec2", "DescribeSecurityGroups", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replayEC2) DescribeReservedInstancesWithContext(ctx aws.Context, in *ec2.DescribeReservedInstancesInput, _ ...request.Option) (*ec2.DescribeReservedInstancesOutput, error) {
+	out := &ec2.DescribeReservedInstancesOutput{}
+	if err := c.rep.load("ec2", "DescribeReservedInstances", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type recordingELBv2 struct {
+	elbv2iface.ELBV2API
+	rec *recorder
+}
+
+func (c *recordingELBv2) DescribeLoadBalancersWithContext(ctx aws.Context, in *elbv2.DescribeLoadBalancersInput, opts ...request.Option) (*elbv2.DescribeLoadBalancersOutput, error) {
+	out, err := c.ELBV2API.DescribeLoadBalancersWithContext(ctx, in, opts...)
+	return out, c.rec.save("elbv2", "DescribeLoadBalancers", in, out, err)
+}
+
+func (c *recordingELBv2) DescribeTagsWithContext(ctx aws.Context, in *elbv2.DescribeTagsInput, opts ...request.Option) (*elbv2.DescribeTagsOutput, error) {
+	out, err := c.ELBV2API.DescribeTagsWithContext(ctx, in, opts...)
+	return out, c.rec.save("elbv2", "DescribeTags", in, out, err)
+}
+
+type replayELBv2 struct {
+	elbv2iface.ELBV2API
+	rep *replayer
+}
+
+func (c *replayELBv2) DescribeLoadBalancersWithContext(ctx aws.Context, in *elbv2.DescribeLoadBalancersInput, _ ...request.Option) (*elbv2.DescribeLoadBalancersOutput, error) {
+	out := &elbv2.DescribeLoadBalancersOutput{}
+	if err := c.rep.load("elbv2", "DescribeLoadBalancers", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *replayELBv2) DescribeTagsWithContext(ctx aws.Context, in *elbv2.DescribeTagsInput, _ ...request.Option) (*elbv2.DescribeTagsOutput, error) {
+	out := &elbv2.DescribeTagsOutput{}
+	if err := c.rep.load("elbv2", "DescribeTags", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type recordingRDS struct {
+	rdsiface.RDSAPI
+	rec *recorder
+}
+
+func (c *recordingRDS) ListTagsForResourceWithContext(ctx aws.Context, in *rds.ListTagsForResourceInput, opts ...request.Option) (*rds.ListTagsForResourceOutput, error) {
+	out, err := c.RDSAPI.ListTagsForResourceWithContext(ctx, in, opts...)
+	return out, c.rec.save("rds", "ListTagsForResource", in, out, err)
+}
+
+type replayRDS struct {
+	rdsiface.RDSAPI
+	rep *replayer
+}
+
+func (c *replayRDS) ListTagsForResourceWithContext(ctx aws.Context, in *rds.ListTagsForResourceInput, _ ...request.Option) (*rds.ListTagsForResourceOutput, error) {
+	out := &rds.ListTagsForResourceOutput{}
+	if err := c.rep.load("rds", "ListTagsForResource", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type recordingRedshift struct {
+	redshiftiface.RedshiftAPI
+	rec *recorder
+}
+
+func (c *recordingRedshift) DescribeClustersWithContext(ctx aws.Context, in *redshift.DescribeClustersInput, opts ...request.Option) (*redshift.DescribeClustersOutput, error) {
+	out, err := c.RedshiftAPI.DescribeClustersWithContext(ctx, in, opts...)
+	return out, c.rec.save("redshift", "DescribeClusters", in, out, err)
+}
+
+type replayRedshift struct {
+	redshiftiface.RedshiftAPI
+	rep *replayer
+}
+
+func (c *replayRedshift) DescribeClustersWithContext(ctx aws.Context, in *redshift.DescribeClustersInput, _ ...request.Option) (*redshift.DescribeClustersOutput, error) {
+	out := &redshift.DescribeClustersOutput{}
+	if err := c.rep.load("redshift", "DescribeClusters", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type recordingSTS struct {
+	stsiface.STSAPI
+	rec *recorder
+}
+
+func (c *recordingSTS) GetCallerIdentityWithContext(ctx aws.Context, in *sts.GetCallerIdentityInput, opts ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	out, err := c.STSAPI.GetCallerIdentityWithContext(ctx, in, opts...)
+	return out, c.rec.save("sts", "GetCallerIdentity", in, out, err)
+}
+
+type replaySTS struct {
+	stsiface.STSAPI
+	rep *replayer
+}
+
+func (c *replaySTS) GetCallerIdentityWithContext(ctx aws.Context, in *sts.GetCallerIdentityInput, _ ...request.Option) (*sts.GetCallerIdentityOutput, error) {
+	out := &sts.GetCallerIdentityOutput{}
+	if err := c.rep.load("sts", "GetCallerIdentity", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type recordingIAM struct {
+	iamiface.IAMAPI
+	rec *recorder
+}
+
+func (c *recordingIAM) ListUserTagsWithContext(ctx aws.Context, in *iam.ListUserTagsInput, opts ...request.Option) (*iam.ListUserTagsOutput, error) {
+	out, err := c.IAMAPI.ListUserTagsWithContext(ctx, in, opts...)
+	return out, c.rec.save("iam", "ListUserTags", in, out, err)
+}
+
+type replayIAM struct {
+	iamiface.IAMAPI
+	rep *replayer
+}
+
+func (c *replayIAM) ListUserTagsWithContext(ctx aws.Context, in *iam.ListUserTagsInput, _ ...request.Option) (*iam.ListUserTagsOutput, error) {
+	out := &iam.ListUserTagsOutput{}
+	if err := c.rep.load("iam", "ListUserTags", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type recordingS3 struct {
+	s3iface.S3API
+	rec *recorder
+}
+
+func (c *recordingS3) GetBucketTaggingWithContext(ctx aws.Context, in *s3.GetBucketTaggingInput, opts ...request.Option) (*s3.GetBucketTaggingOutput, error) {
+	out, err := c.S3API.GetBucketTaggingWithContext(ctx, in, opts...)
+	return out, c.rec.save("s3", "GetBucketTagging", in, out, err)
+}
+
+type replayS3 struct {
+	s3iface.S3API
+	rep *replayer
+}
+
+func (c *replayS3) GetBucketTaggingWithContext(ctx aws.Context, in *s3.GetBucketTaggingInput, _ ...request.Option) (*s3.GetBucketTaggingOutput, error) {
+	out := &s3.GetBucketTaggingOutput{}
+	if err := c.rep.load("s3", "GetBucketTagging", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type recordingELB struct {
+	elbiface.ELBAPI
+	rec *recorder
+}
+
+func (c *recordingELB) DescribeTagsWithContext(ctx aws.Context, in *elb.DescribeTagsInput, opts ...request.Option) (*elb.DescribeTagsOutput, error) {
+	out, err := c.ELBAPI.DescribeTagsWithContext(ctx, in, opts...)
+	return out, c.rec.save("elb", "DescribeTags", in, out, err)
+}
+
+type replayELB struct {
+	elbiface.ELBAPI
+	rep *replayer
+}
+
+func (c *replayELB) DescribeTagsWithContext(ctx aws.Context, in *elb.DescribeTagsInput, _ ...request.Option) (*elb.DescribeTagsOutput, error) {
+	out := &elb.DescribeTagsOutput{}
+	if err := c.rep.load("elb", "DescribeTags", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type recordingAutoScaling struct {
+	autoscalingiface.AutoScalingAPI
+	rec *recorder
+}
+
+func (c *recordingAutoScaling) DescribeTagsWithContext(ctx aws.Context, in *autoscaling.DescribeTagsInput, opts ...request.Option) (*autoscaling.DescribeTagsOutput, error) {
+	out, err := c.AutoScalingAPI.DescribeTagsWithContext(ctx, in, opts...)
+	return out, c.rec.save("autoscaling", "DescribeTags", in, out, err)
+}
+
+type replayAutoScaling struct {
+	autoscalingiface.AutoScalingAPI
+	rep *replayer
+}
+
+func (c *replayAutoScaling) DescribeTagsWithContext(ctx aws.Context, in *autoscaling.DescribeTagsInput, _ ...request.Option) (*autoscaling.DescribeTagsOutput, error) {
+	out := &autoscaling.DescribeTagsOutput{}
+	if err := c.rep.load("autoscaling", "DescribeTags", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type recordingResourceGroupsTagging struct {
+	resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+	rec *recorder
+}
+
+func (c *recordingResourceGroupsTagging) GetResourcesWithContext(ctx aws.Context, in *resourcegroupstaggingapi.GetResourcesInput, opts ...request.Option) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	out, err := c.ResourceGroupsTaggingAPIAPI.GetResourcesWithContext(ctx, in, opts...)
+	return out, c.rec.save("tagging", "GetResources", in, out, err)
+}
+
+type replayResourceGroupsTagging struct {
+	resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI
+	rep *replayer
+}
+
+func (c *replayResourceGroupsTagging) GetResourcesWithContext(ctx aws.Context, in *resourcegroupstaggingapi.GetResourcesInput, _ ...request.Option) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	out := &resourcegroupstaggingapi.GetResourcesOutput{}
+	if err := c.rep.load("tagging", "GetResources", in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,101 @@
+package chanute_test
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+// pollingSupport reports a refresh as processing the first time it's polled, and the check in its cooldown once it has succeeded
+type pollingSupport struct {
+	*chanutetest.Support
+	mu    sync.Mutex
+	polls int
+}
+
+func (s *pollingSupport) DescribeTrustedAdvisorCheckRefreshStatusesWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckRefreshStatusesInput, opts ...request.Option) (*support.DescribeTrustedAdvisorCheckRefreshStatusesOutput, error) {
+	o, err := s.Support.DescribeTrustedAdvisorCheckRefreshStatusesWithContext(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.polls++
+	for _, sts := range o.Statuses {
+		switch {
+		case aws.StringValue(sts.Status) != "success":
+		case s.polls == 2:
+			sts.Status = aws.String("processing")
+		default:
+			sts.MillisUntilNextRefreshable = aws.Int64(int64(time.Hour / time.Millisecond))
+		}
+	}
+	return o, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chanute")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := chanutetest.New()
+	f.Support.AddCheck("Pfx0RwqBli", string(chanute.CheckAmazonS3BucketPermissions), "security", s3BucketPermissionsColumns,
+		[]string{"US East (N. Virginia)", "us-east-1", "local-bucket", "Yes", "No", "Yellow", "No"},
+		[]string{"US West (Oregon)", "us-west-2", "remote-bucket", "No", "No", "Red", "Yes"},
+	)
+	f.S3.AddBucket("local-bucket", map[string]string{"team": "data"})
+	f.InRegion("us-west-2").S3.AddBucket("remote-bucket", map[string]string{"team": "web"})
+
+	options := []chanute.Option{
+		chanute.WithChecks(chanute.CheckAmazonS3BucketPermissions),
+		chanute.WithAggregationByTag("team"),
+		chanute.WithRefresh(time.Minute),
+		chanute.WithRefreshPollInterval(time.Millisecond),
+	}
+
+	c := f.Clients()
+	c.Support = &pollingSupport{Support: f.Support}
+	rec, err := chanute.RecordClients(c, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := chanute.GenerateClientReport(rec, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if polls := c.Support.(*pollingSupport).polls; polls < 3 {
+		t.Fatalf("expected the refresh status to be polled more than once, got %d polls", polls)
+	}
+
+	rep, err := chanute.ReplayClients(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := chanute.GenerateClientReport(rep, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recorded.RefreshErrors) != 0 || len(replayed.RefreshErrors) != 0 {
+		t.Errorf("expected the refresh to succeed, recorded %v, replayed %v", recorded.RefreshErrors, replayed.RefreshErrors)
+	}
+	if got, want := replayed.AsciiReport(), recorded.AsciiReport(); got != want {
+		t.Errorf("replayed report differs from the recording\ngot:\n%s\nexpected:\n%s", got, want)
+	}
+	for _, b := range replayed.Security.S3Buckets {
+		if b.Tags["team"] == "" {
+			t.Errorf("%s: tags weren't replayed", b.Bucket)
+		}
+	}
+}
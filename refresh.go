@@ -0,0 +1,146 @@
+package chanute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/aws/aws-sdk-go/service/support/supportiface"
+)
+
+// defaultRefreshPollInterval is how long to wait between refresh status checks without WithRefreshPollInterval
+const defaultRefreshPollInterval = 5 * time.Second
+
+// WithRefresh refreshes every selected check before reporting, waiting up to timeout for the refreshes to finish.
+// Checks that could not be refreshed are still reported with their cached data, and listed in Report.RefreshErrors.
+func WithRefresh(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.RefreshTimeout = timeout
+	}
+}
+
+// WithRefreshPollInterval sets how long to wait between checking whether refreshes have finished
+func WithRefreshPollInterval(interval time.Duration) Option {
+	return func(c *Config) {
+		c.RefreshPollInterval = interval
+	}
+}
+
+// refreshTrustedAdvisorChecks asks Trusted Advisor to refresh each check and waits for them to complete.
+// Checks still inside their refresh cooldown were refreshed recently, so they are left alone, but still reported.
+func refreshTrustedAdvisorChecks(ctx context.Context, c supportiface.SupportAPI, checks []*support.TrustedAdvisorCheckDescription, timeout, interval time.Duration) []*CheckError {
+	if len(checks) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*support.TrustedAdvisorCheckDescription, len(checks))
+	order := make(map[string]int, len(checks))
+	var ids []*string
+	for idx, ch := range checks {
+		byID[aws.StringValue(ch.Id)] = ch
+		order[aws.StringValue(ch.Id)] = idx
+		ids = append(ids, ch.Id)
+	}
+
+	var failed []*CheckError
+	fail := func(id string, err error) {
+		failed = append(failed, &CheckError{
+			Check: checkFor(byID[id]),
+			ID:    id,
+			Err:   err,
+		})
+	}
+
+	o, err := c.DescribeTrustedAdvisorCheckRefreshStatusesWithContext(ctx, &support.DescribeTrustedAdvisorCheckRefreshStatusesInput{CheckIds: ids})
+	if err != nil {
+		for _, id := range ids {
+			fail(aws.StringValue(id), err)
+		}
+		return failed
+	}
+
+	pending := map[string]bool{}
+	statuses := map[string]bool{}
+	for _, sts := range o.Statuses {
+		id := aws.StringValue(sts.CheckId)
+		statuses[id] = true
+		switch aws.StringValue(sts.Status) {
+		case "enqueued", "processing":
+			pending[id] = true
+			continue
+		}
+		if ms := aws.Int64Value(sts.MillisUntilNextRefreshable); ms > 0 {
+			fail(id, fmt.Errorf("refresh cooldown, refreshable in %s", (time.Duration(ms)*time.Millisecond).Round(time.Second)))
+			continue
+		}
+
+		_, err = c.RefreshTrustedAdvisorCheckWithContext(ctx, &support.RefreshTrustedAdvisorCheckInput{CheckId: sts.CheckId})
+		if err != nil {
+			fail(id, err)
+			continue
+		}
+		pending[id] = true
+	}
+	for _, id := range ids {
+		if !statuses[aws.StringValue(id)] {
+			fail(aws.StringValue(id), errors.New("no refresh status returned"))
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+poll:
+	for len(pending) > 0 {
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			for id := range pending {
+				fail(id, fmt.Errorf("refresh did not finish within %s", timeout))
+			}
+			break
+		}
+		if wait > interval {
+			wait = interval
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			for id := range pending {
+				fail(id, ctx.Err())
+			}
+			break poll
+		}
+
+		var pendingIDs []*string
+		for _, id := range ids {
+			if pending[aws.StringValue(id)] {
+				pendingIDs = append(pendingIDs, id)
+			}
+		}
+		o, err = c.DescribeTrustedAdvisorCheckRefreshStatusesWithContext(ctx, &support.DescribeTrustedAdvisorCheckRefreshStatusesInput{CheckIds: pendingIDs})
+		if err != nil {
+			for id := range pending {
+				fail(id, err)
+			}
+			break
+		}
+
+		for _, sts := range o.Statuses {
+			id := aws.StringValue(sts.CheckId)
+			switch aws.StringValue(sts.Status) {
+			case "success":
+				delete(pending, id)
+			case "abandoned":
+				delete(pending, id)
+				fail(id, errors.New("refresh abandoned"))
+			}
+		}
+	}
+
+	sort.Slice(failed, func(i, j int) bool {
+		return order[failed[i].ID] < order[failed[j].ID]
+	})
+	return failed
+}
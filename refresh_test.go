@@ -0,0 +1,66 @@
+package chanute_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+// forgetfulSupport leaves a check out of refresh statuses
+type forgetfulSupport struct {
+	*chanutetest.Support
+	forget string
+}
+
+func (s *forgetfulSupport) DescribeTrustedAdvisorCheckRefreshStatusesWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckRefreshStatusesInput, opts ...request.Option) (*support.DescribeTrustedAdvisorCheckRefreshStatusesOutput, error) {
+	o, err := s.Support.DescribeTrustedAdvisorCheckRefreshStatusesWithContext(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var statuses []*support.TrustedAdvisorCheckRefreshStatus
+	for _, sts := range o.Statuses {
+		if aws.StringValue(sts.CheckId) != s.forget {
+			statuses = append(statuses, sts)
+		}
+	}
+	o.Statuses = statuses
+	return o, nil
+}
+
+func TestRefreshReportsChecksNotRefreshed(t *testing.T) {
+	f := chanutetest.New()
+	columns := []string{"Region", "Bucket Name", "Status"}
+	f.Support.AddCheck("BueAdJ7NrP", string(chanute.CheckAmazonS3BucketLogging), "fault_tolerance", columns)
+	f.Support.AddCheck("R365s2Qddf", string(chanute.CheckAmazonS3BucketVersioning), "fault_tolerance", columns)
+	f.Support.AddCheck("Pfx0RwqBli", string(chanute.CheckAmazonS3BucketPermissions), "security", columns)
+	f.Support.RefreshCooldowns = map[string]time.Duration{"R365s2Qddf": 90 * time.Second}
+
+	c := f.Clients()
+	c.Support = &forgetfulSupport{Support: f.Support, forget: "Pfx0RwqBli"}
+	r, err := chanute.GenerateClientReport(c, chanute.WithRefresh(time.Minute), chanute.WithRefreshPollInterval(time.Millisecond), chanute.WithChecks(
+		chanute.CheckAmazonS3BucketLogging, chanute.CheckAmazonS3BucketVersioning, chanute.CheckAmazonS3BucketPermissions))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !f.Support.Refreshed["BueAdJ7NrP"] || f.Support.Refreshed["R365s2Qddf"] {
+		t.Errorf("expected only the check without a cooldown to be refreshed, got %v", f.Support.Refreshed)
+	}
+	want := map[string]string{
+		"R365s2Qddf": "refresh cooldown, refreshable in 1m30s",
+		"Pfx0RwqBli": "no refresh status returned",
+	}
+	if len(r.RefreshErrors) != len(want) {
+		t.Fatalf("expected %d refresh errors, got %v", len(want), r.RefreshErrors)
+	}
+	for _, ce := range r.RefreshErrors {
+		if got := ce.Err.Error(); got != want[ce.ID] {
+			t.Errorf("%s: got %q, expected %q", ce.ID, got, want[ce.ID])
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package chanute
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// RegionalTagMap is tags by region, then by resource, since names are only unique within a region
+type RegionalTagMap map[string]TagMap
+
+// Get returns the tags of a resource, region can be an availability zone
+func (m RegionalTagMap) Get(region, id string) map[string]string {
+	return m[regionOf(region)][id]
+}
+
+// regionOf turns an availability zone into its region, "us-east-1a" is "us-east-1"
+func regionOf(s string) string {
+	if n := len(s); n > 1 && unicode.IsLetter(rune(s[n-1])) && unicode.IsDigit(rune(s[n-2])) {
+		return s[:n-1]
+	}
+	return s
+}
+
+// regionalIDs are resource IDs or names by region
+type regionalIDs map[string][]*string
+
+func (ids regionalIDs) add(region, id string) {
+	region = regionOf(region)
+	ids[region] = append(ids[region], aws.String(id))
+}
+
+// tagsByRegion looks up tags with config.TagProvider for each region, up to config.Concurrency regions at once.
+// Resources without a region are global.
+func tagsByRegion(ctx context.Context, c *Clients, config *Config, kind TagKind, ids regionalIDs) (RegionalTagMap, error) {
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var regions []string
+	for region := range ids {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	tags := RegionalTagMap{}
+	var err error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, region := range regions {
+		wg.Add(1)
+		go func(region string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			regionTags, lookupErr := config.TagProvider.Tags(ctx, c, kind, region, ids[region])
+			mu.Lock()
+			defer mu.Unlock()
+			if lookupErr != nil {
+				msg := "looking up " + string(kind) + " tags"
+				if region != "" {
+					msg += " in " + region
+				}
+				err = errs.Append(err, errs.NewWithCause(msg, lookupErr))
+			}
+			tags[region] = regionTags
+		}(region)
+	}
+	wg.Wait()
+
+	return tags, err
+}
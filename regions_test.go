@@ -0,0 +1,102 @@
+package chanute
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+// regionTagProvider tags every resource with its ID, fails in the regions in fail,
+// and records the IDs it was asked for and how many regions it was asked about at once.
+// Lookups wait until two have started, so they overlap.
+type regionTagProvider struct {
+	fail map[string]bool
+
+	mu          sync.Mutex
+	asked       map[string][]string
+	active, max int
+	started     chan struct{}
+}
+
+func (p *regionTagProvider) Tags(_ context.Context, _ *Clients, _ TagKind, region string, ids []*string) (TagMap, error) {
+	p.mu.Lock()
+	p.asked[region] = aws.StringValueSlice(ids)
+	p.active++
+	if p.active > p.max {
+		p.max = p.active
+	}
+	if p.active == 2 {
+		select {
+		case <-p.started:
+		default:
+			close(p.started)
+		}
+	}
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.active--
+		p.mu.Unlock()
+	}()
+	<-p.started
+
+	if p.fail[region] {
+		return nil, errors.New("throttled")
+	}
+	tags := TagMap{}
+	for _, id := range ids {
+		tags[aws.StringValue(id)] = map[string]string{"id": aws.StringValue(id)}
+	}
+	return tags, nil
+}
+
+func TestTagsByRegion(t *testing.T) {
+	p := &regionTagProvider{fail: map[string]bool{"eu-west-1": true}, asked: map[string][]string{}, started: make(chan struct{})}
+
+	ids := regionalIDs{}
+	ids.add("us-east-1a", "i-1")
+	ids.add("us-east-1b", "i-2")
+	ids.add("us-west-2", "i-3")
+	ids.add("eu-west-1c", "i-4")
+	ids.add("", "global")
+
+	tags, err := tagsByRegion(context.Background(), &Clients{}, &Config{TagProvider: p, Concurrency: 2}, TagKindEC2Instance, ids)
+	if err == nil || !strings.Contains(err.Error(), "looking up ec2:instance tags in eu-west-1") {
+		t.Errorf("expected the eu-west-1 lookup to fail, got %v", err)
+	}
+
+	// availability zones are looked up in their region
+	want := map[string][]string{"": {"global"}, "eu-west-1": {"i-4"}, "us-east-1": {"i-1", "i-2"}, "us-west-2": {"i-3"}}
+	for region := range p.asked {
+		sort.Strings(p.asked[region])
+	}
+	if !reflect.DeepEqual(p.asked, want) {
+		t.Errorf("looked up %v, expected %v", p.asked, want)
+	}
+	if p.max != 2 {
+		t.Errorf("expected 2 regions to be looked up at once, got %d", p.max)
+	}
+
+	// the regions that didn't fail keep their tags
+	for _, r := range []struct {
+		region, id string
+		tagged     bool
+	}{
+		{"us-east-1a", "i-1", true},
+		{"us-east-1", "i-2", true},
+		{"us-west-2", "i-3", true},
+		{"", "global", true},
+		{"eu-west-1c", "i-4", false},
+		{"us-west-2", "i-1", false},
+	} {
+		if got := tags.Get(r.region, r.id)["id"] == r.id; got != r.tagged {
+			t.Errorf("%s %s: tagged is %t, expected %t", r.region, r.id, got, r.tagged)
+		}
+	}
+}
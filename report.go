@@ -1,12 +1,16 @@
 package chanute
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/aws/aws-sdk-go/service/support/supportiface"
 	"github.com/richardwilkes/toolbox/errs"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
@@ -24,14 +28,33 @@ func PrintDollars(i int) string {
 
 type Report struct {
 	Config *Config
+	// Account is the account ID, it's only looked up when aggregating
+	Account string
 
 	CostOptimization *CostReport
 	ServiceLimits    *LimitReport
+	Security         *SecurityReport
+	FaultTolerance   *FaultToleranceReport
+	Performance      *PerformanceReport
+	// GenericChecks are the selected checks without a typed report
+	GenericChecks map[Check]*GenericCheckReport
+	// Summary is only set when using WithSummaryOnly
+	Summary *SummaryReport
+
+	// CheckErrors are the checks whose results couldn't be fetched
+	CheckErrors []*CheckError
+	// RefreshErrors are the checks that couldn't be refreshed, and are reported with cached data
+	RefreshErrors []*CheckError
+	// MissingChecks are configured checks that Trusted Advisor no longer has, by ID or name
+	MissingChecks []Check
 }
 
 func (r *Report) AsciiReport() string {
 	o := &strings.Builder{}
 
+	if r.Summary != nil {
+		o.WriteString(r.Summary.AsciiReport())
+	}
 	if r.CostOptimization != nil {
 		o.WriteString(r.CostOptimization.AsciiReport())
 		o.WriteString("\n")
@@ -40,16 +63,79 @@ func (r *Report) AsciiReport() string {
 		o.WriteString(r.ServiceLimits.AsciiReport())
 		o.WriteString("\n")
 	}
+	if r.Security != nil {
+		o.WriteString(r.Security.AsciiReport())
+		o.WriteString("\n")
+	}
+	if r.FaultTolerance != nil {
+		o.WriteString(r.FaultTolerance.AsciiReport())
+		o.WriteString("\n")
+	}
+	if r.Performance != nil {
+		o.WriteString(r.Performance.AsciiReport())
+		o.WriteString("\n")
+	}
+	for _, g := range sortedGenericChecks(r.GenericChecks) {
+		o.WriteString(g.AsciiReport())
+		o.WriteString("\n")
+	}
+	if len(r.CheckErrors) > 0 {
+		o.WriteString("Failed Checks\n")
+		Table(o, []string{"Check", "ID", "Error"}, checkErrorRows(r.CheckErrors))
+		o.WriteString("\n")
+	}
+	if len(r.RefreshErrors) > 0 {
+		o.WriteString("Checks Not Refreshed\n")
+		Table(o, []string{"Check", "ID", "Error"}, checkErrorRows(r.RefreshErrors))
+		o.WriteString("\n")
+	}
+	if len(r.MissingChecks) > 0 {
+		o.WriteString("Checks Not Found\n")
+		var rows [][]string
+		for _, c := range r.MissingChecks {
+			rows = append(rows, []string{string(c), c.ID()})
+		}
+		Table(o, []string{"Check", "ID"}, rows)
+		o.WriteString("\n")
+	}
 
 	return o.String()
 }
 
+func checkErrorRows(ces []*CheckError) [][]string {
+	var rows [][]string
+	for _, ce := range ces {
+		msg := ce.Err.Error()
+		// skip the stack trace
+		if e, ok := ce.Err.(*errs.Error); ok {
+			msg = e.Message()
+		}
+		rows = append(rows, []string{string(ce.Check), ce.ID, msg})
+	}
+	return rows
+}
+
 func GenerateReport(sess *session.Session, options ...Option) (*Report, error) {
-	return generateReport(sess, configFromOptions(options...))
+	return GenerateReportWithContext(context.Background(), sess, options...)
+}
+
+// GenerateReportWithContext is GenerateReport, but every AWS call is made with ctx
+func GenerateReportWithContext(ctx context.Context, sess *session.Session, options ...Option) (*Report, error) {
+	return generateReport(ctx, NewClients(sess), configFromOptions(options...))
+}
+
+// GenerateClientReport is GenerateReport for callers that provide their own clients, such as fakes
+func GenerateClientReport(c *Clients, options ...Option) (*Report, error) {
+	return GenerateClientReportWithContext(context.Background(), c, options...)
+}
+
+// GenerateClientReportWithContext is GenerateClientReport, but every AWS call is made with ctx
+func GenerateClientReportWithContext(ctx context.Context, c *Clients, options ...Option) (*Report, error) {
+	return generateReport(ctx, c, configFromOptions(options...))
 }
 
 func configFromOptions(options ...Option) *Config {
-	cfg := &Config{}
+	cfg := &Config{Concurrency: defaultConcurrency, EnvironmentConcurrency: defaultConcurrency, Language: defaultLanguage}
 	for _, o := range options {
 		o(cfg)
 	}
@@ -59,23 +145,55 @@ func configFromOptions(options ...Option) *Config {
 	return cfg
 }
 
-func generateReport(sess *session.Session, cfg *Config) (*Report, error) {
+func generateReport(ctx context.Context, c *Clients, cfg *Config) (*Report, error) {
 
 	activeChecks := make(map[Check]bool, len(cfg.Checks))
 	for _, c := range cfg.Checks {
 		activeChecks[c] = true
 	}
 
-	checks, err := ListNonOKTrustedAdvisorChecks(sess, activeChecks)
+	descriptions, english, err := describeTrustedAdvisorChecks(ctx, c.Support, activeChecks, cfg.Language)
 	if err != nil {
 		return nil, errs.Wrap(err)
 	}
+	missing := missingChecks(activeChecks, descriptions, english)
+
+	var refreshErrs []*CheckError
+	if cfg.RefreshTimeout > 0 {
+		interval := cfg.RefreshPollInterval
+		if interval <= 0 {
+			interval = defaultRefreshPollInterval
+		}
+		refreshErrs = refreshTrustedAdvisorChecks(ctx, c.Support, descriptions, cfg.RefreshTimeout, interval)
+	}
+
+	if cfg.SummaryOnly {
+		summaries, err := summarizeTrustedAdvisorChecks(ctx, c.Support, descriptions)
+		return &Report{
+			Config:        cfg,
+			Summary:       &SummaryReport{Checks: summaries},
+			RefreshErrors: refreshErrs,
+			MissingChecks: missing,
+		}, err
+	}
+
+	checks, checkErrs := fetchTrustedAdvisorChecks(ctx, c.Support, descriptions, english, cfg.Concurrency)
+	if ctx.Err() != nil {
+		return nil, errs.Wrap(ctx.Err())
+	}
+	for _, ce := range checkErrs {
+		err = errs.Append(err, ce)
+	}
 
 	var lookups = map[CheckType]map[Check][]*TrustedAdvisorCheck{}
+	var generic = map[Check][]*TrustedAdvisorCheck{}
 	for _, check := range checks {
-		chk := Check(check.Name)
+		chk := LookupCheck(check.ID, check.Name)
 		if !activeChecks[chk] {
-			fmt.Printf("skipping %s\n", check.Name)
+			continue
+		}
+		if !typedChecks[chk] {
+			generic[chk] = append(generic[chk], check)
 			continue
 		}
 		if chkType, ok := checkTypeLookup[chk]; ok {
@@ -83,27 +201,50 @@ func generateReport(sess *session.Session, cfg *Config) (*Report, error) {
 				lookups[chkType] = map[Check][]*TrustedAdvisorCheck{}
 			}
 			lookups[chkType][chk] = append(lookups[chkType][chk], check)
-			continue
 		}
-		fmt.Printf("%s not supported\n", check.Name)
 	}
 
 	r := &Report{
-		Config: cfg,
+		Config:        cfg,
+		CheckErrors:   checkErrs,
+		RefreshErrors: refreshErrs,
+		MissingChecks: missing,
+	}
+
+	// the reports are built with the account added to the tags aggregators are given, r.Config is left as it was
+	buildCfg := cfg
+	if cfg.Aggregator != nil {
+		identity, stsErr := c.STS.GetCallerIdentityWithContext(ctx, &sts.GetCallerIdentityInput{})
+		if stsErr != nil {
+			err = errs.Append(err, errs.NewWithCause("looking up the account to aggregate by", stsErr))
+		} else {
+			r.Account = aws.StringValue(identity.Account)
+			withAccountCfg := *cfg
+			withAccountCfg.Aggregator = withAccount(cfg.Aggregator, r.Account)
+			buildCfg = &withAccountCfg
+		}
 	}
 
-	var reportErr error
+	if len(generic) > 0 {
+		r.GenericChecks = make(map[Check]*GenericCheckReport, len(generic))
+		for chk, values := range generic {
+			r.GenericChecks[chk] = genericCheckReport(cfg, chk, values)
+		}
+	}
 
 	for chk, values := range lookups {
+		var reportErr error
 		switch chk {
 		case CheckTypeCost:
-			r.CostOptimization, reportErr = costReport(cfg, sess, values)
+			r.CostOptimization, reportErr = costReport(ctx, buildCfg, c, values)
 		case CheckTypeServiceLimit:
-			r.ServiceLimits, reportErr = serviceLimits(cfg, sess, values)
+			r.ServiceLimits, reportErr = serviceLimits(ctx, buildCfg, c, values)
+		case CheckTypeSecurity:
+			r.Security, reportErr = securityReport(ctx, buildCfg, c, values)
 		case CheckTypeFaultTolerance:
-			// r.FaultTolerance, err = faultTolerance(cfg, sess, values)
-		default:
-			printUnhandled(chk, values)
+			r.FaultTolerance, reportErr = faultToleranceReport(ctx, buildCfg, c, values)
+		case CheckTypePerformance:
+			r.Performance, reportErr = performanceReport(ctx, buildCfg, c, values)
 		}
 		if reportErr != nil {
 			err = errs.Append(err, reportErr)
@@ -116,80 +257,195 @@ func generateReport(sess *session.Session, cfg *Config) (*Report, error) {
 type TrustedAdvisorCheck struct {
 	Name               string
 	ID                 string
+	Category           string
 	Status             string
 	Description        string
 	Flagged, Processed int64
+	// EstimatedMonthlySavings is only set in summary mode
+	EstimatedMonthlySavings int
 
 	// Check is used to get the high-level description of a check
 	Check *support.TrustedAdvisorCheckDescription
+	// English is the English description of the check, which names the metadata columns for DecodeResources
+	English *support.TrustedAdvisorCheckDescription
 	// Result is used to get detailed information about which resources are failing a check
 	Result *support.TrustedAdvisorCheckResult
+	// Summary is only set in summary mode
+	Summary *support.TrustedAdvisorCheckSummary
 }
 
 // ListNonOKTrustedAdvisorChecks queries Trusted Advisor and only returns checks that have a status of error or warning
 // These are typically worth review, and opening a ticket to increase limits.
-func ListNonOKTrustedAdvisorChecks(sess *session.Session, activeChecks map[Check]bool) ([]*TrustedAdvisorCheck, error) {
-	c := support.New(sess)
-	o, err := c.DescribeTrustedAdvisorChecks(&support.DescribeTrustedAdvisorChecksInput{Language: aws.String("en")})
+// Results are fetched concurrently, but returned in the order Trusted Advisor lists the checks.
+// Checks that fail are added to the error, and every check that succeeded is still returned.
+func ListNonOKTrustedAdvisorChecks(c supportiface.SupportAPI, activeChecks map[Check]bool) ([]*TrustedAdvisorCheck, error) {
+	return ListNonOKTrustedAdvisorChecksWithContext(context.Background(), c, activeChecks)
+}
+
+// ListNonOKTrustedAdvisorChecksWithContext is ListNonOKTrustedAdvisorChecks, but every AWS call is made with ctx
+func ListNonOKTrustedAdvisorChecksWithContext(ctx context.Context, c supportiface.SupportAPI, activeChecks map[Check]bool) ([]*TrustedAdvisorCheck, error) {
+	results, checkErrs, err := listNonOKTrustedAdvisorChecks(ctx, c, activeChecks, defaultConcurrency)
 	if err != nil {
 		return nil, err
 	}
+	for _, ce := range checkErrs {
+		err = errs.Append(err, ce)
+	}
+	return results, err
+}
+
+// CheckError records a check whose results couldn't be fetched
+type CheckError struct {
+	Check Check
+	ID    string
+	Err   error
+}
+
+func (e *CheckError) Error() string {
+	return fmt.Sprintf("%s (%s): %s", e.Check, e.ID, e.Err)
+}
+
+func listNonOKTrustedAdvisorChecks(ctx context.Context, c supportiface.SupportAPI, activeChecks map[Check]bool, concurrency int) ([]*TrustedAdvisorCheck, []*CheckError, error) {
+	descriptions, english, err := describeTrustedAdvisorChecks(ctx, c, activeChecks, defaultLanguage)
+	if err != nil {
+		return nil, nil, err
+	}
+	results, checkErrs := fetchTrustedAdvisorChecks(ctx, c, descriptions, english, concurrency)
+	return results, checkErrs, nil
+}
 
-	var results []*TrustedAdvisorCheck
-	for _, ch := range o.Checks {
-		if len(activeChecks) > 0 && !activeChecks[Check(aws.StringValue(ch.Name))] {
-			continue
-		}
+// describeTrustedAdvisorChecks lists the active checks, or every check if none are active.
+// Checks are matched by ID, so they're still found if AWS renames them.
+// The English descriptions are returned by ID too, since they're needed to name the metadata columns in other languages.
+func describeTrustedAdvisorChecks(ctx context.Context, c supportiface.SupportAPI, activeChecks map[Check]bool, language string) ([]*support.TrustedAdvisorCheckDescription, map[string]*support.TrustedAdvisorCheckDescription, error) {
+	if language == "" {
+		language = defaultLanguage
+	}
+	o, err := c.DescribeTrustedAdvisorChecksWithContext(ctx, &support.DescribeTrustedAdvisorChecksInput{Language: aws.String(language)})
+	if err != nil {
+		return nil, nil, err
+	}
 
-		cho, err2 := c.DescribeTrustedAdvisorCheckResult(&support.DescribeTrustedAdvisorCheckResultInput{CheckId: ch.Id})
-		if err2 != nil {
-			err = errs.Append(err, err2)
-			continue
+	englishChecks := o.Checks
+	if language != defaultLanguage {
+		eo, err := c.DescribeTrustedAdvisorChecksWithContext(ctx, &support.DescribeTrustedAdvisorChecksInput{Language: aws.String(defaultLanguage)})
+		if err != nil {
+			return nil, nil, err
 		}
+		englishChecks = eo.Checks
+	}
+	english := make(map[string]*support.TrustedAdvisorCheckDescription, len(englishChecks))
+	for _, ch := range englishChecks {
+		english[aws.StringValue(ch.Id)] = ch
+	}
 
-		if cho.Result == nil {
-			err = errs.Append(err, errs.New("result or resources summary nil"))
+	var descriptions []*support.TrustedAdvisorCheckDescription
+	for _, ch := range o.Checks {
+		if len(activeChecks) > 0 && !activeChecks[checkFor(englishFor(ch, english))] {
 			continue
 		}
+		descriptions = append(descriptions, ch)
+	}
+	return descriptions, english, nil
+}
+
+// englishFor returns the English description of ch, or ch if there isn't one
+func englishFor(ch *support.TrustedAdvisorCheckDescription, english map[string]*support.TrustedAdvisorCheckDescription) *support.TrustedAdvisorCheckDescription {
+	if en, ok := english[aws.StringValue(ch.Id)]; ok {
+		return en
+	}
+	return ch
+}
 
-		var flagged int64
-		var processed int64
-		if cho.Result.ResourcesSummary != nil {
-			flagged = aws.Int64Value(cho.Result.ResourcesSummary.ResourcesFlagged)
-			processed = aws.Int64Value(cho.Result.ResourcesSummary.ResourcesProcessed)
+func fetchTrustedAdvisorChecks(ctx context.Context, c supportiface.SupportAPI, descriptions []*support.TrustedAdvisorCheckDescription, english map[string]*support.TrustedAdvisorCheckDescription, concurrency int) ([]*TrustedAdvisorCheck, []*CheckError) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// each worker writes to its own index, which keeps the output in the same order as the input
+	fetched := make([]*TrustedAdvisorCheck, len(descriptions))
+	fetchErrs := make([]error, len(descriptions))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				fetched[idx], fetchErrs[idx] = fetchTrustedAdvisorCheck(ctx, c, descriptions[idx], englishFor(descriptions[idx], english))
+			}
+		}()
+	}
+	for idx := range descriptions {
+		// select picks at random when a worker is free too, so check for cancellation first
+		if ctx.Err() == nil {
+			select {
+			case jobs <- idx:
+				continue
+			case <-ctx.Done():
+			}
 		}
+		// nothing else is sent once cancelled, so the remaining checks fail with the context's error
+		for ; idx < len(descriptions); idx++ {
+			fetchErrs[idx] = ctx.Err()
+		}
+		break
+	}
+	close(jobs)
+	wg.Wait()
 
-		if aws.StringValue(cho.Result.Status) == "ok" {
+	var results []*TrustedAdvisorCheck
+	var checkErrs []*CheckError
+	for idx, ch := range descriptions {
+		if fetchErrs[idx] != nil {
+			checkErrs = append(checkErrs, &CheckError{
+				Check: checkFor(englishFor(ch, english)),
+				ID:    aws.StringValue(ch.Id),
+				Err:   fetchErrs[idx],
+			})
 			continue
 		}
+		if fetched[idx] != nil {
+			results = append(results, fetched[idx])
+		}
+	}
+	return results, checkErrs
+}
 
-		results = append(results, &TrustedAdvisorCheck{
-			Name:        aws.StringValue(ch.Name),
-			ID:          aws.StringValue(ch.Id),
-			Status:      aws.StringValue(cho.Result.Status),
-			Flagged:     flagged,
-			Processed:   processed,
-			Description: aws.StringValue(ch.Description),
+// fetchTrustedAdvisorCheck returns nil if the check is ok
+func fetchTrustedAdvisorCheck(ctx context.Context, c supportiface.SupportAPI, ch, en *support.TrustedAdvisorCheckDescription) (*TrustedAdvisorCheck, error) {
+	cho, err := c.DescribeTrustedAdvisorCheckResultWithContext(ctx, &support.DescribeTrustedAdvisorCheckResultInput{CheckId: ch.Id})
+	if err != nil {
+		return nil, err
+	}
 
-			Check:  ch,
-			Result: cho.Result,
-		})
+	if cho.Result == nil {
+		return nil, errs.New("result or resources summary nil")
 	}
-	return results, err
-}
 
-func checksToMaps(checks []*TrustedAdvisorCheck) []map[string]string {
-	var o []map[string]string
-	for _, check := range checks {
-		for _, res := range check.Result.FlaggedResources {
-			mapped := map[string]string{}
-			for idx, md := range check.Check.Metadata {
-				if len(res.Metadata) > idx {
-					mapped[aws.StringValue(md)] = aws.StringValue(res.Metadata[idx])
-				}
-			}
-			o = append(o, mapped)
-		}
+	var flagged int64
+	var processed int64
+	if cho.Result.ResourcesSummary != nil {
+		flagged = aws.Int64Value(cho.Result.ResourcesSummary.ResourcesFlagged)
+		processed = aws.Int64Value(cho.Result.ResourcesSummary.ResourcesProcessed)
+	}
+
+	if aws.StringValue(cho.Result.Status) == "ok" {
+		return nil, nil
 	}
-	return o
+
+	return &TrustedAdvisorCheck{
+		Name:        aws.StringValue(ch.Name),
+		ID:          aws.StringValue(ch.Id),
+		Category:    aws.StringValue(ch.Category),
+		Status:      aws.StringValue(cho.Result.Status),
+		Flagged:     flagged,
+		Processed:   processed,
+		Description: aws.StringValue(ch.Description),
+
+		Check:   ch,
+		English: en,
+		Result:  cho.Result,
+	}, nil
 }
@@ -1,9 +1,9 @@
 package chanute
 
 import (
+	"context"
 	"strings"
 
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/richardwilkes/toolbox/errs"
 )
 
@@ -14,33 +14,55 @@ type CostReport struct {
 	RDS           *RDSReport
 	Redshift      *RedshiftReport
 	EIPs          *UnassociatedElasticIPAddressesReport
+	// ReservedInstances has both reserved instance checks
+	ReservedInstances *ReservedInstanceReport
 }
 
-func costReport(cfg *Config, sess *session.Session, lookups map[Check][]*TrustedAdvisorCheck) (*CostReport, error) {
+// costReportChecks are the cost checks with a typed report
+var costReportChecks = []Check{
+	CheckLowUtilizationAmazonEC2Instances,
+	CheckIdleLoadBalancers,
+	CheckUnderutilizedAmazonEBSVolumes,
+	CheckAmazonRDSIdleDBInstances,
+	CheckUnderutilizedAmazonRedshiftClusters,
+	CheckUnassociatedElasticIPAddresses,
+	CheckAmazonEC2ReservedInstanceLeaseExpiration,
+	CheckAmazonEC2ReservedInstancesOptimization,
+}
+
+func costReport(ctx context.Context, cfg *Config, c *Clients, lookups map[Check][]*TrustedAdvisorCheck) (*CostReport, error) {
 	r := &CostReport{}
 	var err error
+	riLookups := map[Check][]*TrustedAdvisorCheck{}
 	for lookup, values := range lookups {
 		var reportErr error
 		switch lookup {
 		case CheckLowUtilizationAmazonEC2Instances:
-			r.EC2, reportErr = ec2LowUtilization(cfg, sess, values)
+			r.EC2, reportErr = ec2LowUtilization(ctx, cfg, c, values)
 		case CheckIdleLoadBalancers:
-			r.LoadBalancers, reportErr = idleLoadBalancers(cfg, sess, values)
+			r.LoadBalancers, reportErr = idleLoadBalancers(ctx, cfg, c, values)
 		case CheckUnderutilizedAmazonEBSVolumes:
-			r.EBS, reportErr = ebsLowUtilization(cfg, sess, values)
+			r.EBS, reportErr = ebsLowUtilization(ctx, cfg, c, values)
 		case CheckAmazonRDSIdleDBInstances:
-			r.RDS, reportErr = rdsIdleInstances(cfg, sess, values)
+			r.RDS, reportErr = rdsIdleInstances(ctx, cfg, c, values)
 		case CheckUnderutilizedAmazonRedshiftClusters:
-			r.Redshift, reportErr = redshiftLowUtilization(cfg, sess, values)
+			r.Redshift, reportErr = redshiftLowUtilization(ctx, cfg, c, values)
 		case CheckUnassociatedElasticIPAddresses:
-			r.EIPs, reportErr = unassociatedElasticIPAddresses(cfg, sess, values)
-		default:
-			printUnhandledCheck(CheckTypeCost, lookup, values)
+			r.EIPs, reportErr = unassociatedElasticIPAddresses(ctx, cfg, c, values)
+		case CheckAmazonEC2ReservedInstanceLeaseExpiration, CheckAmazonEC2ReservedInstancesOptimization:
+			riLookups[lookup] = values
 		}
 		if reportErr != nil {
 			err = errs.Append(err, reportErr)
 		}
 	}
+
+	if len(riLookups) > 0 {
+		var reportErr error
+		if r.ReservedInstances, reportErr = reservedInstances(ctx, cfg, c, riLookups); reportErr != nil {
+			err = errs.Append(err, reportErr)
+		}
+	}
 	return r, err
 }
 
@@ -71,6 +93,10 @@ func (r *CostReport) AsciiReport() string {
 		o.WriteString(r.EIPs.AsciiReport())
 		o.WriteString("\n")
 	}
+	if r.ReservedInstances != nil {
+		o.WriteString(r.ReservedInstances.AsciiReport())
+		o.WriteString("\n")
+	}
 
 	return o.String()
 }
@@ -95,5 +121,8 @@ func (r *CostReport) AggregateRows(a Aggregator) []*AggregateRow {
 	if r.EIPs != nil {
 		o = append(o, r.EIPs.AggregateRows(a)...)
 	}
+	if r.ReservedInstances != nil {
+		o = append(o, r.ReservedInstances.AggregateRows(a)...)
+	}
 	return o
 }
@@ -1,12 +1,12 @@
 package chanute
 
 import (
+	"context"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/olekukonko/tablewriter"
 	"github.com/richardwilkes/toolbox/errs"
@@ -16,9 +16,20 @@ type EBSReport struct {
 	Volumes    []*EBSVolume
 	Aggregated []*EBSAggregate
 	Errors     []string
+	Suppressed []*EBSVolume
 }
 
+var ebsHeaders = []string{"Name", "ID", "Size (in GB)", "Monthly Cost"}
+
 func (r *EBSReport) AsciiReport() string {
+	var suppressed [][]string
+	for _, v := range r.Suppressed {
+		suppressed = append(suppressed, v.row())
+	}
+	return withSuppressed(r.asciiReport(), "EBS", ebsHeaders, suppressed)
+}
+
+func (r *EBSReport) asciiReport() string {
 	if len(r.Volumes) == 0 {
 		return "EBS: No issues"
 	}
@@ -27,11 +38,11 @@ func (r *EBSReport) AsciiReport() string {
 	o.WriteString("EBS\n")
 
 	w := tablewriter.NewWriter(o)
-	w.SetHeader([]string{"Name", "ID", "Size (in GB)", "Monthly Cost"})
+	w.SetHeader(ebsHeaders)
 
 	if r.Aggregated == nil {
 		for _, v := range r.Volumes {
-			w.Append([]string{v.Name, v.ID, strconv.Itoa(v.Size), PrintDollars(v.MonthlyStorageCost)})
+			w.Append(v.row())
 		}
 		w.Render()
 		return o.String()
@@ -42,7 +53,7 @@ func (r *EBSReport) AsciiReport() string {
 
 		if len(agg.Volumes) > 0 {
 			for _, v := range agg.Volumes {
-				w.Append([]string{v.Name, v.ID, strconv.Itoa(v.Size), PrintDollars(v.MonthlyStorageCost)})
+				w.Append(v.row())
 			}
 			w.Append([]string{"", "", "", ""})
 		}
@@ -60,55 +71,45 @@ type EBSAggregate struct {
 }
 
 type EBSVolume struct {
-	ID                 string
-	Name               string
-	Type               string
-	Region             string
-	MonthlyStorageCost int
-	Size               int
+	FlaggedResource
 
-	SnapshotID   string
-	SnapshotName string
-	SnapshotAge  string
+	ID                 string `ta:"Volume ID"`
+	Name               string `ta:"Volume Name"`
+	Type               string `ta:"Volume Type"`
+	Region             string `ta:"Region"`
+	MonthlyStorageCost int    `ta:"Monthly Storage Cost,dollars"`
+	Size               int    `ta:"Volume Size"`
+
+	SnapshotID   string `ta:"Snapshot ID"`
+	SnapshotName string `ta:"Snapshot Name"`
+	SnapshotAge  string `ta:"Snapshot Age"`
 
 	Tags map[string]string
 }
 
-func ebsLowUtilization(config *Config, sess *session.Session, checks []*TrustedAdvisorCheck) (*EBSReport, error) {
-	m := checksToMaps(checks)
+func (v *EBSVolume) row() []string {
+	return []string{v.Name, v.ID, strconv.Itoa(v.Size), PrintDollars(v.MonthlyStorageCost)}
+}
 
+func ebsLowUtilization(ctx context.Context, config *Config, c *Clients, checks []*TrustedAdvisorCheck) (*EBSReport, error) {
 	r := &EBSReport{}
-	volumes := make(map[string]*EBSVolume, len(checks))
-
-	for _, volume := range m {
-		volumeID := volume["Volume ID"]
-		volumes[volumeID] = &EBSVolume{
-			ID:                 volumeID,
-			Name:               volume["Volume Name"],
-			Type:               volume["Volume Type"],
-			Size:               parseAmount(volume["Volume Size"]),
-			MonthlyStorageCost: parseAmount(volume["Monthly Storage Cost"]),
-			Region:             volume["Region"],
-
-			SnapshotID:   volume["Snapshot ID"],
-			SnapshotName: volume["Snapshot Name"],
-			SnapshotAge:  volume["Snapshot Age"],
-		}
+	var decoded []*EBSVolume
+	err := decodeReportResources(config, checks, &decoded, &r.Suppressed)
+
+	volumes := make(map[string]*EBSVolume, len(decoded))
+	ids := regionalIDs{}
+	for _, v := range decoded {
+		ids.add(v.Region, v.ID)
+		volumes[v.ID] = v
 	}
 
-	if config.GetTags {
-		var ids []*string
-		for _, v := range r.Volumes {
-			ids = append(ids, aws.String(v.ID))
-		}
-		allTags, err := GetEBSTags(sess, ids)
-		if err != nil {
-			return nil, errs.Wrap(err)
+	if config.TagProvider != nil {
+		allTags, tagErr := tagsByRegion(ctx, c, config, TagKindEBSVolume, ids)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
 		}
-		for id, tags := range allTags {
-			if v, ok := volumes[id]; ok {
-				v.Tags = tags
-			}
+		for _, v := range volumes {
+			v.Tags = allTags.Get(v.Region, v.ID)
 		}
 	}
 
@@ -117,13 +118,16 @@ func ebsLowUtilization(config *Config, sess *session.Session, checks []*TrustedA
 	}
 
 	sort.Slice(r.Volumes, func(i, j int) bool {
-		return r.Volumes[i].MonthlyStorageCost > r.Volumes[j].MonthlyStorageCost
+		if r.Volumes[i].MonthlyStorageCost != r.Volumes[j].MonthlyStorageCost {
+			return r.Volumes[i].MonthlyStorageCost > r.Volumes[j].MonthlyStorageCost
+		}
+		return r.Volumes[i].ID < r.Volumes[j].ID
 	})
 
 	if config.Aggregator != nil {
 		aggregated := map[string]*EBSAggregate{}
 		for _, v := range r.Volumes {
-			key := config.Aggregator(v.Tags)
+			key := config.Aggregator(aggregationTags(v.Tags, v.Name, v.ID))
 			if key == "" {
 				key = v.Name
 				if key == "" {
@@ -145,16 +149,22 @@ func ebsLowUtilization(config *Config, sess *session.Session, checks []*TrustedA
 		}
 
 		sort.Slice(r.Aggregated, func(i, j int) bool {
-			return r.Aggregated[i].MonthlyStorageCost > r.Aggregated[j].MonthlyStorageCost
+			if r.Aggregated[i].MonthlyStorageCost != r.Aggregated[j].MonthlyStorageCost {
+				return r.Aggregated[i].MonthlyStorageCost > r.Aggregated[j].MonthlyStorageCost
+			}
+			return r.Aggregated[i].Key < r.Aggregated[j].Key
 		})
 	}
 
-	return r, nil
+	return r, err
 }
 
-func GetEBSTags(sess *session.Session, ids []*string) (TagMap, error) {
-	c := ec2.New(sess)
+// GetEBSTags is GetEBSTagsWithContext using a background context
+func GetEBSTags(c *Clients, ids []*string) (TagMap, error) {
+	return GetEBSTagsWithContext(context.Background(), c, ids)
+}
 
+func GetEBSTagsWithContext(ctx context.Context, c *Clients, ids []*string) (TagMap, error) {
 	tags := map[string]map[string]string{}
 
 	input := &ec2.DescribeVolumesInput{
@@ -162,7 +172,7 @@ func GetEBSTags(sess *session.Session, ids []*string) (TagMap, error) {
 	}
 
 	for {
-		page, err := c.DescribeVolumes(input)
+		page, err := c.EC2.DescribeVolumesWithContext(ctx, input)
 		if err != nil {
 			errStr := err.Error()
 
@@ -216,9 +226,11 @@ func (r *EBSReport) AggregateRows(a Aggregator) []*AggregateRow {
 }
 func (v *EBSVolume) AggregateRow(a Aggregator) *AggregateRow {
 	return &AggregateRow{
-		Env:            "",
 		Service:        "EBS",
-		Key:            a(v.Tags),
+		Region:         regionOf(v.Region),
+		ID:             v.ID,
+		Name:           v.Name,
+		Key:            a(aggregationTags(v.Tags, v.Name, v.ID)),
 		MonthlySavings: v.MonthlyStorageCost,
 	}
 }
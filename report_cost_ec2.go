@@ -1,13 +1,12 @@
 package chanute
 
 import (
-	"fmt"
+	"context"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/olekukonko/tablewriter"
 	"github.com/richardwilkes/toolbox/errs"
@@ -17,9 +16,20 @@ type EC2Report struct {
 	Instances  []*EC2Instance
 	Aggregated []*EC2Aggregate
 	Errors     []string
+	Suppressed []*EC2Instance
 }
 
+var ec2Headers = []string{"Name", "ID", "Low Utilization Days", "Estimated Monthly Savings"}
+
 func (r *EC2Report) AsciiReport() string {
+	var suppressed [][]string
+	for _, i := range r.Suppressed {
+		suppressed = append(suppressed, i.row())
+	}
+	return withSuppressed(r.asciiReport(), "EC2", ec2Headers, suppressed)
+}
+
+func (r *EC2Report) asciiReport() string {
 	if len(r.Instances) == 0 {
 		return "EC2: No issues"
 	}
@@ -28,11 +38,11 @@ func (r *EC2Report) AsciiReport() string {
 	o.WriteString("EC2\n")
 
 	w := tablewriter.NewWriter(o)
-	w.SetHeader([]string{"Name", "ID", "Low Utilization Days", "Estimated Monthly Savings"})
+	w.SetHeader(ec2Headers)
 
 	if r.Aggregated == nil {
 		for _, i := range r.Instances {
-			w.Append([]string{i.Name, i.ID, strconv.Itoa(i.LowUtilizationDays), strconv.Itoa(i.EstimatedMonthlySavings)})
+			w.Append(i.row())
 		}
 		w.Render()
 		return o.String()
@@ -43,7 +53,7 @@ func (r *EC2Report) AsciiReport() string {
 
 		if len(agg.Instances) > 0 {
 			for _, i := range agg.Instances {
-				w.Append([]string{i.Name, i.ID, strconv.Itoa(i.LowUtilizationDays), strconv.Itoa(i.EstimatedMonthlySavings)})
+				w.Append(i.row())
 			}
 			w.Append([]string{"", "", "", ""})
 		}
@@ -60,73 +70,61 @@ type EC2Aggregate struct {
 }
 
 type EC2Instance struct {
-	Name     string
-	ID       string
-	Type     string
-	RegionAZ string
-
-	EstimatedMonthlySavings int
-
-	LowUtilizationDays  int
-	Network14DayAverage string
-	CPU14DayAverage     string
-
-	Day1, Day2, Day3, Day4, Day5, Day6, Day7, Day8, Day9, Day10, Day11, Day12, Day13, Day14 string
+	FlaggedResource
+
+	Name     string `ta:"Instance Name"`
+	ID       string `ta:"Instance ID"`
+	Type     string `ta:"Instance Type"`
+	RegionAZ string `ta:"Region/AZ"`
+
+	EstimatedMonthlySavings int `ta:"Estimated Monthly Savings,dollars"`
+
+	LowUtilizationDays  int    `ta:"Number of Days Low Utilization,days"`
+	Network14DayAverage string `ta:"14-Day Average Network I/O"`
+	CPU14DayAverage     string `ta:"14-Day Average CPU Utilization"`
+
+	Day1  string `ta:"Day 1"`
+	Day2  string `ta:"Day 2"`
+	Day3  string `ta:"Day 3"`
+	Day4  string `ta:"Day 4"`
+	Day5  string `ta:"Day 5"`
+	Day6  string `ta:"Day 6"`
+	Day7  string `ta:"Day 7"`
+	Day8  string `ta:"Day 8"`
+	Day9  string `ta:"Day 9"`
+	Day10 string `ta:"Day 10"`
+	Day11 string `ta:"Day 11"`
+	Day12 string `ta:"Day 12"`
+	Day13 string `ta:"Day 13"`
+	Day14 string `ta:"Day 14"`
 
 	Tags map[string]string
 }
 
-func ec2LowUtilization(config *Config, sess *session.Session, checks []*TrustedAdvisorCheck) (*EC2Report, error) {
+func (i *EC2Instance) row() []string {
+	return []string{i.Name, i.ID, strconv.Itoa(i.LowUtilizationDays), strconv.Itoa(i.EstimatedMonthlySavings)}
+}
+
+func ec2LowUtilization(ctx context.Context, config *Config, c *Clients, checks []*TrustedAdvisorCheck) (*EC2Report, error) {
 	r := &EC2Report{}
 
-	m := checksToMaps(checks)
-
-	instances := make(map[string]*EC2Instance, len(checks))
-	var ids []*string
-	for _, instance := range m {
-
-		instanceID := instance["Instance ID"]
-		ids = append(ids, aws.String(instanceID))
-
-		instances[instanceID] = &EC2Instance{
-			Name:     instance["Instance Name"],
-			ID:       instanceID,
-			Type:     instance["Instance Type"],
-			RegionAZ: instance["Region/AZ"],
-
-			EstimatedMonthlySavings: parseAmount(instance["Estimated Monthly Savings"]),
-
-			LowUtilizationDays: parseDays(instance["Number of Days Low Utilization"]),
-
-			Network14DayAverage: instance["14-Day Average Network I/O"],
-			CPU14DayAverage:     instance["14-Day Average CPU Utilization"],
-
-			Day1:  instance["Day 1"],
-			Day2:  instance["Day 2"],
-			Day3:  instance["Day 3"],
-			Day4:  instance["Day 4"],
-			Day5:  instance["Day 5"],
-			Day6:  instance["Day 6"],
-			Day7:  instance["Day 7"],
-			Day8:  instance["Day 8"],
-			Day9:  instance["Day 9"],
-			Day10: instance["Day 10"],
-			Day11: instance["Day 11"],
-			Day12: instance["Day 12"],
-			Day13: instance["Day 13"],
-			Day14: instance["Day 14"],
-		}
+	var decoded []*EC2Instance
+	err := decodeReportResources(config, checks, &decoded, &r.Suppressed)
+
+	instances := make(map[string]*EC2Instance, len(decoded))
+	ids := regionalIDs{}
+	for _, i := range decoded {
+		ids.add(i.RegionAZ, i.ID)
+		instances[i.ID] = i
 	}
 
-	if config.GetTags {
-		allTags, err := GetEC2Tags(sess, ids)
-		if err != nil {
-			return nil, errs.Wrap(err)
+	if config.TagProvider != nil {
+		allTags, tagErr := tagsByRegion(ctx, c, config, TagKindEC2Instance, ids)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
 		}
-		for id, tags := range allTags {
-			if in, ok := instances[id]; ok {
-				in.Tags = tags
-			}
+		for _, i := range instances {
+			i.Tags = allTags.Get(i.RegionAZ, i.ID)
 		}
 	}
 
@@ -135,13 +133,16 @@ func ec2LowUtilization(config *Config, sess *session.Session, checks []*TrustedA
 	}
 
 	sort.Slice(r.Instances, func(i, j int) bool {
-		return r.Instances[i].EstimatedMonthlySavings > r.Instances[j].EstimatedMonthlySavings
+		if r.Instances[i].EstimatedMonthlySavings != r.Instances[j].EstimatedMonthlySavings {
+			return r.Instances[i].EstimatedMonthlySavings > r.Instances[j].EstimatedMonthlySavings
+		}
+		return r.Instances[i].ID < r.Instances[j].ID
 	})
 
 	if config.Aggregator != nil {
 		aggregated := map[string]*EC2Aggregate{}
 		for _, i := range r.Instances {
-			key := config.Aggregator(i.Tags)
+			key := config.Aggregator(aggregationTags(i.Tags, i.Name, i.ID))
 			if key == "" {
 				key = i.Name
 				if key == "" {
@@ -164,33 +165,38 @@ func ec2LowUtilization(config *Config, sess *session.Session, checks []*TrustedA
 		}
 
 		sort.Slice(r.Aggregated, func(i, j int) bool {
-			return r.Aggregated[i].EstimatedMonthlySavings > r.Aggregated[j].EstimatedMonthlySavings
+			if r.Aggregated[i].EstimatedMonthlySavings != r.Aggregated[j].EstimatedMonthlySavings {
+				return r.Aggregated[i].EstimatedMonthlySavings > r.Aggregated[j].EstimatedMonthlySavings
+			}
+			return r.Aggregated[i].Key < r.Aggregated[j].Key
 		})
 	}
 
-	return r, nil
+	return r, err
 }
 
 func ec2TagsToMap(t []*ec2.Tag) map[string]string {
 	o := map[string]string{}
+	// EC2 tag keys are unique per resource
 	for _, tag := range t {
-		if _, ok := o[*tag.Key]; ok {
-			fmt.Println("already a value!")
-		}
 		o[*tag.Key] = *tag.Value
 	}
 	return o
 }
 
-func GetEC2Tags(sess *session.Session, ids []*string) (TagMap, error) {
-	c := ec2.New(sess)
+// GetEC2Tags is GetEC2TagsWithContext using a background context
+func GetEC2Tags(c *Clients, ids []*string) (TagMap, error) {
+	return GetEC2TagsWithContext(context.Background(), c, ids)
+}
+
+func GetEC2TagsWithContext(ctx context.Context, c *Clients, ids []*string) (TagMap, error) {
 	input := &ec2.DescribeInstancesInput{
 		InstanceIds: ids,
 	}
 
 	tags := map[string]map[string]string{}
 	for {
-		page, err := c.DescribeInstances(input)
+		page, err := c.EC2.DescribeInstancesWithContext(ctx, input)
 		if err != nil {
 			errStr := err.Error()
 
@@ -246,9 +252,11 @@ func (r *EC2Report) AggregateRows(a Aggregator) []*AggregateRow {
 }
 func (i *EC2Instance) AggregateRow(a Aggregator) *AggregateRow {
 	return &AggregateRow{
-		Env:            "",
 		Service:        "EC2",
-		Key:            a(i.Tags),
+		Region:         regionOf(i.RegionAZ),
+		ID:             i.ID,
+		Name:           i.Name,
+		Key:            a(aggregationTags(i.Tags, i.Name, i.ID)),
 		MonthlySavings: i.EstimatedMonthlySavings,
 	}
 }
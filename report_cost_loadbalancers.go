@@ -1,12 +1,12 @@
 package chanute
 
 import (
-	"fmt"
+	"context"
 	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/olekukonko/tablewriter"
 	"github.com/richardwilkes/toolbox/errs"
@@ -15,6 +15,7 @@ import (
 type LoadBalancerReport struct {
 	LoadBalancers []*LoadBalancer
 	Aggregated    []*LoadBalancerAggregate
+	Suppressed    []*LoadBalancer
 }
 
 type LoadBalancerAggregate struct {
@@ -24,15 +25,31 @@ type LoadBalancerAggregate struct {
 }
 
 type LoadBalancer struct {
-	Region                  string
-	Name                    string
-	Reason                  string
-	EstimatedMonthlySavings int
+	FlaggedResource
+
+	Region                  string `ta:"Region"`
+	Name                    string `ta:"Load Balancer Name"`
+	Reason                  string `ta:"Reason"`
+	EstimatedMonthlySavings int    `ta:"Estimated Monthly Savings,dollars"`
 
 	Tags map[string]string
 }
 
+func (lb *LoadBalancer) row() []string {
+	return []string{lb.Name, lb.Region, lb.Reason, PrintDollars(lb.EstimatedMonthlySavings)}
+}
+
+var loadBalancerHeaders = []string{"Name", "Region", "Reason", "Monthly Cost"}
+
 func (r *LoadBalancerReport) AsciiReport() string {
+	var suppressed [][]string
+	for _, lb := range r.Suppressed {
+		suppressed = append(suppressed, lb.row())
+	}
+	return withSuppressed(r.asciiReport(), "Load Balancers", loadBalancerHeaders, suppressed)
+}
+
+func (r *LoadBalancerReport) asciiReport() string {
 	if len(r.LoadBalancers) == 0 {
 		return "Load Balancers: No issues"
 	}
@@ -41,11 +58,11 @@ func (r *LoadBalancerReport) AsciiReport() string {
 	o.WriteString("Load Balancers\n")
 
 	w := tablewriter.NewWriter(o)
-	w.SetHeader([]string{"Name", "Region", "Reason", "Monthly Cost"})
+	w.SetHeader(loadBalancerHeaders)
 
 	if r.Aggregated == nil {
 		for _, lb := range r.LoadBalancers {
-			w.Append([]string{lb.Name, lb.Region, lb.Reason, PrintDollars(lb.EstimatedMonthlySavings)})
+			w.Append(lb.row())
 		}
 		w.Render()
 		return o.String()
@@ -56,7 +73,7 @@ func (r *LoadBalancerReport) AsciiReport() string {
 
 		if len(agg.LoadBalancers) > 0 {
 			for _, lb := range agg.LoadBalancers {
-				w.Append([]string{lb.Name, lb.Region, lb.Reason, PrintDollars(lb.EstimatedMonthlySavings)})
+				w.Append(lb.row())
 			}
 			w.Append([]string{"", "", "", ""})
 		}
@@ -66,49 +83,43 @@ func (r *LoadBalancerReport) AsciiReport() string {
 	return o.String()
 }
 
-func idleLoadBalancers(config *Config, sess *session.Session, checks []*TrustedAdvisorCheck) (*LoadBalancerReport, error) {
-	m := checksToMaps(checks)
-
-	lbs := make(map[string]*LoadBalancer, len(checks))
-	var names []*string
-	for _, instance := range m {
-		lb := &LoadBalancer{
-			Region:                  instance["Region"],
-			Name:                    instance["Load Balancer Name"],
-			Reason:                  instance["Reason"],
-			EstimatedMonthlySavings: parseAmount(instance["Estimated Monthly Savings"]),
-		}
-		names = append(names, aws.String(lb.Name))
-		lbs[lb.Name] = lb
+func idleLoadBalancers(ctx context.Context, config *Config, c *Clients, checks []*TrustedAdvisorCheck) (*LoadBalancerReport, error) {
+	r := &LoadBalancerReport{}
+	var decoded []*LoadBalancer
+	err := decodeReportResources(config, checks, &decoded, &r.Suppressed)
+
+	lbs := make(map[string]*LoadBalancer, len(decoded))
+	names := regionalIDs{}
+	for _, lb := range decoded {
+		names.add(lb.Region, lb.Name)
+		lbs[lb.Region+"/"+lb.Name] = lb
 	}
 
-	if config.GetTags {
-		tags, err := GetLBTagsFromNames(sess, names)
-		if err != nil {
-			return nil, errs.Wrap(err)
+	if config.TagProvider != nil {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindLoadBalancer, names)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
 		}
-		for name, lbTags := range tags {
-			lb, ok := lbs[name]
-			if !ok {
-				continue
-			}
-			lb.Tags = lbTags
+		for _, lb := range lbs {
+			lb.Tags = tags.Get(lb.Region, lb.Name)
 		}
 	}
 
-	r := &LoadBalancerReport{}
 	for _, lb := range lbs {
 		r.LoadBalancers = append(r.LoadBalancers, lb)
 	}
 
 	sort.Slice(r.LoadBalancers, func(i, j int) bool {
-		return r.LoadBalancers[i].EstimatedMonthlySavings > r.LoadBalancers[j].EstimatedMonthlySavings
+		if r.LoadBalancers[i].EstimatedMonthlySavings != r.LoadBalancers[j].EstimatedMonthlySavings {
+			return r.LoadBalancers[i].EstimatedMonthlySavings > r.LoadBalancers[j].EstimatedMonthlySavings
+		}
+		return r.LoadBalancers[i].Name < r.LoadBalancers[j].Name
 	})
 
 	if config.Aggregator != nil {
 		aggregated := map[string]*LoadBalancerAggregate{}
 		for _, i := range r.LoadBalancers {
-			key := config.Aggregator(i.Tags)
+			key := config.Aggregator(aggregationTags(i.Tags, i.Name, ""))
 			if key == "" {
 				key = i.Name
 			}
@@ -127,28 +138,37 @@ func idleLoadBalancers(config *Config, sess *session.Session, checks []*TrustedA
 		}
 
 		sort.Slice(r.Aggregated, func(i, j int) bool {
-			return r.Aggregated[i].EstimatedMonthlySavings > r.Aggregated[j].EstimatedMonthlySavings
+			if r.Aggregated[i].EstimatedMonthlySavings != r.Aggregated[j].EstimatedMonthlySavings {
+				return r.Aggregated[i].EstimatedMonthlySavings > r.Aggregated[j].EstimatedMonthlySavings
+			}
+			return r.Aggregated[i].Key < r.Aggregated[j].Key
 		})
 	}
 
-	return r, nil
+	return r, err
 }
 
-func GetLBTagsFromNames(sess *session.Session, names []*string) (TagMap, error) {
-	c := elbv2.New(sess)
+// GetLBTagsFromNames is GetLBTagsFromNamesWithContext using a background context
+func GetLBTagsFromNames(c *Clients, names []*string) (TagMap, error) {
+	return GetLBTagsFromNamesWithContext(context.Background(), c, names)
+}
+
+func GetLBTagsFromNamesWithContext(ctx context.Context, c *Clients, names []*string) (TagMap, error) {
 	lbs := stringPtrSet(names)
 
 	input := &elbv2.DescribeLoadBalancersInput{}
 	fauxMarker := aws.String("marker")
 	var arns []*string
+	arnNames := map[string]string{}
 
 	for {
 		// only change the names if we aren't paginating
 		if input.Marker == nil {
 			input.Names = names
-			if len(names) > 20 {
-				input.Names = names[0:20]
-				names = names[20:]
+			names = nil
+			if len(input.Names) > 20 {
+				names = input.Names[20:]
+				input.Names = input.Names[0:20]
 			}
 		}
 		if input.Marker == fauxMarker {
@@ -159,26 +179,12 @@ func GetLBTagsFromNames(sess *session.Session, names []*string) (TagMap, error)
 			break
 		}
 
-		page, err := c.DescribeLoadBalancers(input)
+		page, err := c.ELBv2.DescribeLoadBalancersWithContext(ctx, input)
 		if err != nil {
-			errStr := err.Error()
-
-			if !strings.HasPrefix(errStr, "LoadBalancerNotFound") {
-				return nil, errs.Wrap(err)
-			}
-
-			// if instances are not found, pull them out of the input
-			start := strings.Index(errStr, "'[")
-			end := strings.LastIndex(errStr, "]'")
-			if start == -1 || end == -1 || start == end {
-				return nil, errs.New("couldn't find two ' chars in error message")
-			}
-
-			idsStr := errStr[start+2 : end]
-			idsToRemove := strings.Split(idsStr, ", ")
-			nonExisting := make(map[string]bool, len(idsToRemove))
-			for _, ec2ID := range idsToRemove {
-				nonExisting[ec2ID] = true
+			// if load balancers are not found, pull them out of the input
+			nonExisting, notFoundErr := notFoundLoadBalancers(err)
+			if notFoundErr != nil {
+				return nil, notFoundErr
 			}
 
 			var newNames []*string
@@ -187,6 +193,9 @@ func GetLBTagsFromNames(sess *session.Session, names []*string) (TagMap, error)
 					newNames = append(newNames, lbName)
 				}
 			}
+			if len(newNames) == len(input.Names) {
+				return nil, errs.Wrap(err)
+			}
 
 			input.Names = newNames
 			input.Marker = fauxMarker
@@ -194,79 +203,98 @@ func GetLBTagsFromNames(sess *session.Session, names []*string) (TagMap, error)
 		}
 
 		for _, lb := range page.LoadBalancers {
-			if _, ok := lbs[lb.LoadBalancerName]; ok {
+			if lbs[aws.StringValue(lb.LoadBalancerName)] {
 				arns = append(arns, lb.LoadBalancerArn)
+				arnNames[aws.StringValue(lb.LoadBalancerArn)] = aws.StringValue(lb.LoadBalancerName)
 			}
 		}
 		input.Marker = page.NextMarker
 	}
-	return GetLBTagsFromARNs(sess, arns)
+
+	arnTags, err := GetLBTagsFromARNsWithContext(ctx, c, arns)
+	if err != nil {
+		return nil, err
+	}
+
+	// key tags by name, which is what Trusted Advisor reports
+	tags := make(TagMap, len(arnTags))
+	for arn, t := range arnTags {
+		tags[arnNames[arn]] = t
+	}
+	return tags, nil
 }
 
-func GetLBTagsFromARNs(sess *session.Session, arns []*string) (TagMap, error) {
-	c := elbv2.New(sess)
+// GetLBTagsFromARNs is GetLBTagsFromARNsWithContext using a background context
+func GetLBTagsFromARNs(c *Clients, arns []*string) (TagMap, error) {
+	return GetLBTagsFromARNsWithContext(context.Background(), c, arns)
+}
+
+func GetLBTagsFromARNsWithContext(ctx context.Context, c *Clients, arns []*string) (TagMap, error) {
 	tags := map[string]map[string]string{}
-	for {
+	for len(arns) > 0 {
 		input := &elbv2.DescribeTagsInput{ResourceArns: arns}
-
-		input.ResourceArns = arns
 		if len(arns) > 20 {
 			input.ResourceArns = arns[0:20]
-			arns = arns[20:]
 		}
+		arns = arns[len(input.ResourceArns):]
+
+		for len(input.ResourceArns) > 0 {
+			page, err := c.ELBv2.DescribeTagsWithContext(ctx, input)
+			if err != nil {
+				// if load balancers are not found, pull them out of the input and try again
+				nonExisting, notFoundErr := notFoundLoadBalancers(err)
+				if notFoundErr != nil {
+					return nil, notFoundErr
+				}
 
-		if len(input.ResourceArns) == 0 {
-			break
-		}
-
-		page, err := c.DescribeTags(input)
-		if err != nil {
-			fmt.Println(err)
-			errStr := err.Error()
-
-			if !strings.HasPrefix(errStr, "LoadBalancerNotFound") {
-				return nil, errs.Wrap(err)
-			}
-
-			// if instances are not found, pull them out of the input
-			start := strings.Index(errStr, "'[")
-			end := strings.LastIndex(errStr, "]'")
-			if start == -1 || end == -1 || start == end {
-				return nil, errs.New("couldn't find two ' chars in error message")
-			}
-
-			idsStr := errStr[start+2 : end]
-			idsToRemove := strings.Split(idsStr, ", ")
-			nonExisting := make(map[string]bool, len(idsToRemove))
-			for _, id := range idsToRemove {
-				nonExisting[id] = true
-			}
-
-			var newArns []*string
-			for _, lbArn := range input.ResourceArns {
-				if !nonExisting[aws.StringValue(lbArn)] {
-					newArns = append(newArns, lbArn)
+				var newArns []*string
+				for _, lbArn := range input.ResourceArns {
+					if !nonExisting[aws.StringValue(lbArn)] {
+						newArns = append(newArns, lbArn)
+					}
+				}
+				if len(newArns) == len(input.ResourceArns) {
+					return nil, errs.Wrap(err)
 				}
+				input.ResourceArns = newArns
+				continue
 			}
 
-			input.ResourceArns = newArns
-			continue
-		}
-
-		for _, res := range page.TagDescriptions {
-			arn := aws.StringValue(res.ResourceArn)
-			tags[arn] = make(map[string]string, len(res.Tags))
-			for _, t := range res.Tags {
-				tags[arn][aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+			for _, res := range page.TagDescriptions {
+				arn := aws.StringValue(res.ResourceArn)
+				tags[arn] = make(map[string]string, len(res.Tags))
+				for _, t := range res.Tags {
+					tags[arn][aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+				}
 			}
-		}
-		if len(arns) <= len(input.ResourceArns) {
 			break
 		}
 	}
 	return tags, nil
 }
 
+// notFoundLoadBalancers are the names or ARNs in a LoadBalancerNotFound error, any other error is returned wrapped
+func notFoundLoadBalancers(err error) (map[string]bool, error) {
+	ae, ok := err.(awserr.Error)
+	if !ok || ae.Code() != elbv2.ErrCodeLoadBalancerNotFoundException {
+		return nil, errs.Wrap(err)
+	}
+
+	msg := ae.Message()
+	start := strings.Index(msg, "'[")
+	end := strings.LastIndex(msg, "]'")
+	if start == -1 || end == -1 || start == end {
+		return nil, errs.NewWithCause("couldn't find two ' chars in error message", err)
+	}
+
+	idsToRemove := strings.Split(msg[start+2:end], ", ")
+	nonExisting := make(map[string]bool, len(idsToRemove))
+	for _, id := range idsToRemove {
+		nonExisting[id] = true
+	}
+	return nonExisting, nil
+}
+
 func (r *LoadBalancerReport) AggregateRows(a Aggregator) []*AggregateRow {
 	var o []*AggregateRow
 	for _, l := range r.LoadBalancers {
@@ -277,9 +305,11 @@ func (r *LoadBalancerReport) AggregateRows(a Aggregator) []*AggregateRow {
 
 func (l *LoadBalancer) AggregateRow(a Aggregator) *AggregateRow {
 	return &AggregateRow{
-		Env:            "",
-		Service:        "EC2",
-		Key:            a(l.Tags),
+		Service:        "ELB",
+		Region:         regionOf(l.Region),
+		ID:             l.Name,
+		Name:           l.Name,
+		Key:            a(aggregationTags(l.Tags, l.Name, "")),
 		MonthlySavings: l.EstimatedMonthlySavings,
 	}
 }
@@ -0,0 +1,36 @@
+package chanute_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+func TestGetLBTagsFromARNsSkipsMissing(t *testing.T) {
+	f := chanutetest.New()
+	var arns []string
+	for i := 0; i < 45; i++ {
+		arn := fmt.Sprintf("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/lb-%d/1", i)
+		arns = append(arns, arn)
+		// every third load balancer has been deleted
+		if i%3 != 0 {
+			f.ELBv2.AddLoadBalancer(fmt.Sprintf("lb-%d", i), arn, map[string]string{"team": "web"})
+		}
+	}
+
+	tags, err := chanute.GetLBTagsFromARNs(f.Clients(), aws.StringSlice(arns))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 30 {
+		t.Errorf("expected tags for 30 load balancers, got %d", len(tags))
+	}
+	for _, arn := range arns[1:3] {
+		if tags[arn]["team"] != "web" {
+			t.Errorf("%s: expected team tag, got %v", arn, tags[arn])
+		}
+	}
+}
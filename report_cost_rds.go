@@ -1,13 +1,13 @@
 package chanute
 
 import (
-	"fmt"
+	"context"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/rds"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/olekukonko/tablewriter"
@@ -17,6 +17,7 @@ import (
 type RDSReport struct {
 	Instances  []*RDSInstance
 	Aggregated []*RDSAggregate
+	Suppressed []*RDSInstance
 }
 
 type RDSAggregate struct {
@@ -27,17 +28,33 @@ type RDSAggregate struct {
 }
 
 type RDSInstance struct {
-	Region                  string
-	Name                    string
-	Type                    string
-	MultiAZ                 bool
-	StorageProvisionedGB    int
-	DaysSinceLastConnection int
-	EstimatedMonthlySavings int
+	FlaggedResource
+
+	Region                  string `ta:"Region"`
+	Name                    string `ta:"DB Instance Name"`
+	Type                    string `ta:"Instance Type"`
+	MultiAZ                 bool   `ta:"Multi-AZ"`
+	StorageProvisionedGB    int    `ta:"Storage Provisioned (GB)"`
+	DaysSinceLastConnection int    `ta:"Days Since Last Connection,days"`
+	EstimatedMonthlySavings int    `ta:"Estimated Monthly Savings (On Demand),dollars"`
 	Tags                    map[string]string
 }
 
+func (i *RDSInstance) row() []string {
+	return []string{i.Name, strconv.FormatBool(i.MultiAZ), strconv.Itoa(i.DaysSinceLastConnection), strconv.Itoa(i.StorageProvisionedGB), PrintDollars(i.EstimatedMonthlySavings)}
+}
+
+var rdsHeaders = []string{"Name", "MultiAZ", "Days Since Connection", "Storage Size (in GB)", "Monthly Cost"}
+
 func (r *RDSReport) AsciiReport() string {
+	var suppressed [][]string
+	for _, i := range r.Suppressed {
+		suppressed = append(suppressed, i.row())
+	}
+	return withSuppressed(r.asciiReport(), "RDS", rdsHeaders, suppressed)
+}
+
+func (r *RDSReport) asciiReport() string {
 	if len(r.Instances) == 0 {
 		return "RDS: No issues"
 	}
@@ -46,11 +63,11 @@ func (r *RDSReport) AsciiReport() string {
 	o.WriteString("RDS\n")
 
 	w := tablewriter.NewWriter(o)
-	w.SetHeader([]string{"Name", "MultiAZ", "Days Since Connection", "Storage Size (in GB)", "Monthly Cost"})
+	w.SetHeader(rdsHeaders)
 
 	if r.Aggregated == nil {
 		for _, i := range r.Instances {
-			w.Append([]string{i.Name, strconv.FormatBool(i.MultiAZ), strconv.Itoa(i.DaysSinceLastConnection), strconv.Itoa(i.StorageProvisionedGB), PrintDollars(i.EstimatedMonthlySavings)})
+			w.Append(i.row())
 		}
 		w.Render()
 		return o.String()
@@ -61,7 +78,7 @@ func (r *RDSReport) AsciiReport() string {
 
 		if len(agg.Instances) > 0 {
 			for _, i := range agg.Instances {
-				w.Append([]string{i.Name, strconv.FormatBool(i.MultiAZ), strconv.Itoa(i.DaysSinceLastConnection), strconv.Itoa(i.StorageProvisionedGB), PrintDollars(i.EstimatedMonthlySavings)})
+				w.Append(i.row())
 			}
 			w.Append([]string{"", "", "", ""})
 		}
@@ -71,54 +88,42 @@ func (r *RDSReport) AsciiReport() string {
 	return o.String()
 }
 
-func rdsIdleInstances(config *Config, sess *session.Session, checks []*TrustedAdvisorCheck) (*RDSReport, error) {
-	m := checksToMaps(checks)
-
-	instances := make(map[string]*RDSInstance, len(checks))
-	var names []*string
-	for _, instance := range m {
-		name := instance["DB Instance Name"]
-		// names = append(names, aws.String(name))
-		ri := &RDSInstance{
-			Region:                  instance["Region"],
-			Name:                    name,
-			MultiAZ:                 instance["Multi-AZ"] == "Yes",
-			Type:                    instance["Instance Type"],
-			StorageProvisionedGB:    parseAmount(instance["Storage Provisioned (GB)"]),
-			DaysSinceLastConnection: parseDays(instance["Days Since Last Connection"]),
-			EstimatedMonthlySavings: parseAmount(instance["Estimated Monthly Savings (On Demand)"]),
-		}
-		instances[ri.Name] = ri
-		names = append(names, &ri.Name)
+func rdsIdleInstances(ctx context.Context, config *Config, c *Clients, checks []*TrustedAdvisorCheck) (*RDSReport, error) {
+	r := &RDSReport{}
+	var decoded []*RDSInstance
+	err := decodeReportResources(config, checks, &decoded, &r.Suppressed)
+
+	instances := make(map[string]*RDSInstance, len(decoded))
+	names := regionalIDs{}
+	for _, ri := range decoded {
+		instances[ri.Region+"/"+ri.Name] = ri
+		names.add(ri.Region, ri.Name)
 	}
 
-	if config.GetTags {
-		tags, err := GetRDSTags(sess, names)
-		if err != nil {
-			return nil, errs.Wrap(err)
+	if config.TagProvider != nil {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindRDSInstance, names)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
 		}
-		for name, instanceTags := range tags {
-			i, ok := instances[name]
-			if !ok {
-				continue
-			}
-			i.Tags = instanceTags
+		for _, i := range instances {
+			i.Tags = tags.Get(i.Region, i.Name)
 		}
 	}
 
-	r := &RDSReport{}
-
 	for _, instance := range instances {
 		r.Instances = append(r.Instances, instance)
 	}
 	sort.Slice(r.Instances, func(i, j int) bool {
-		return r.Instances[i].EstimatedMonthlySavings > r.Instances[j].EstimatedMonthlySavings
+		if r.Instances[i].EstimatedMonthlySavings != r.Instances[j].EstimatedMonthlySavings {
+			return r.Instances[i].EstimatedMonthlySavings > r.Instances[j].EstimatedMonthlySavings
+		}
+		return r.Instances[i].Name < r.Instances[j].Name
 	})
 
 	if config.Aggregator != nil {
 		aggregated := map[string]*RDSAggregate{}
 		for _, i := range r.Instances {
-			key := config.Aggregator(i.Tags)
+			key := config.Aggregator(aggregationTags(i.Tags, i.Name, ""))
 			if key == "" {
 				key = i.Name
 			}
@@ -139,36 +144,44 @@ func rdsIdleInstances(config *Config, sess *session.Session, checks []*TrustedAd
 		}
 
 		sort.Slice(r.Aggregated, func(i, j int) bool {
-			return r.Aggregated[i].EstimatedMonthlySavings > r.Aggregated[j].EstimatedMonthlySavings
+			if r.Aggregated[i].EstimatedMonthlySavings != r.Aggregated[j].EstimatedMonthlySavings {
+				return r.Aggregated[i].EstimatedMonthlySavings > r.Aggregated[j].EstimatedMonthlySavings
+			}
+			return r.Aggregated[i].Key < r.Aggregated[j].Key
 		})
 	}
 
-	return r, nil
+	return r, err
 }
 
-func GetRDSTags(sess *session.Session, names []*string) (TagMap, error) {
-	c := rds.New(sess)
+// GetRDSTags is GetRDSTagsWithContext using a background context
+func GetRDSTags(c *Clients, names []*string) (TagMap, error) {
+	return GetRDSTagsWithContext(context.Background(), c, names)
+}
 
-	svc := sts.New(sess)
+// GetRDSTagsWithContext looks up DB instances by their ARN in c's region and partition
+func GetRDSTagsWithContext(ctx context.Context, c *Clients, names []*string) (TagMap, error) {
 	input := &sts.GetCallerIdentityInput{}
 
-	result, err := svc.GetCallerIdentity(input)
+	result, err := c.STS.GetCallerIdentityWithContext(ctx, input)
 	if err != nil {
 		return nil, errs.Wrap(err)
 	}
 
 	tags := map[string]map[string]string{}
 	for _, n := range names {
-		arn := fmt.Sprintf("arn:aws:rds:%s:%s:db:%s", *sess.Config.Region, *result.Account, *n)
-		resp, err := c.ListTagsForResource(&rds.ListTagsForResourceInput{
+		arn, err := ResourceARN(c.partition(), TagKindRDSInstance, c.Region, aws.StringValue(result.Account), aws.StringValue(n))
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.RDS.ListTagsForResourceWithContext(ctx, &rds.ListTagsForResourceInput{
 			ResourceName: &arn,
 		})
 		if err != nil {
-			if strings.HasPrefix(err.Error(), "DBInstanceNotFound") {
+			if ae, ok := err.(awserr.Error); ok && ae.Code() == rds.ErrCodeDBInstanceNotFoundFault {
 				continue
 			}
-			fmt.Println(err)
-			continue
+			return nil, errs.Wrap(err)
 		}
 
 		tags[aws.StringValue(n)] = map[string]string{}
@@ -187,9 +200,11 @@ func (r *RDSReport) AggregateRows(a Aggregator) []*AggregateRow {
 }
 func (i *RDSInstance) AggregateRow(a Aggregator) *AggregateRow {
 	return &AggregateRow{
-		Env:            "",
-		Service:        "EC2",
-		Key:            a(i.Tags),
+		Service:        "RDS",
+		Region:         regionOf(i.Region),
+		ID:             i.Name,
+		Name:           i.Name,
+		Key:            a(aggregationTags(i.Tags, i.Name, "")),
 		MonthlySavings: i.EstimatedMonthlySavings,
 	}
 }
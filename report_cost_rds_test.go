@@ -0,0 +1,81 @@
+package chanute_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+var rdsIdleColumns = []string{"Region", "DB Instance Name", "Multi-AZ", "Instance Type", "Storage Provisioned (GB)", "Days Since Last Connection", "Estimated Monthly Savings (On Demand)"}
+
+// throttledRDS fails every tag lookup
+type throttledRDS struct {
+	*chanutetest.RDS
+	calls int
+}
+
+func (r *throttledRDS) ListTagsForResourceWithContext(aws.Context, *rds.ListTagsForResourceInput, ...request.Option) (*rds.ListTagsForResourceOutput, error) {
+	r.calls++
+	return nil, awserr.New("Throttling", "Rate exceeded", nil)
+}
+
+func TestGetRDSTags(t *testing.T) {
+	f := chanutetest.New()
+	f.RDS.AddInstance("arn:aws:rds:us-east-1:123456789012:db:orders", map[string]string{"team": "data"})
+
+	tags, err := chanute.GetRDSTags(f.Clients(), aws.StringSlice([]string{"orders", "deleted"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags["orders"]["team"] != "data" {
+		t.Errorf("expected tags for orders only, got %v", tags)
+	}
+}
+
+func TestGetRDSTagsStopsOnError(t *testing.T) {
+	f := chanutetest.New()
+	c := f.Clients()
+	r := &throttledRDS{RDS: f.RDS}
+	c.RDS = r
+
+	if _, err := chanute.GetRDSTags(c, aws.StringSlice([]string{"a", "b", "c"})); err == nil {
+		t.Error("expected an error")
+	}
+	if r.calls != 1 {
+		t.Errorf("expected 1 call, got %d", r.calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := chanute.GetRDSTagsWithContext(ctx, f.Clients(), aws.StringSlice([]string{"a"})); err == nil {
+		t.Error("expected a cancelled context to fail")
+	}
+}
+
+func TestRDSReportKeepsInstancesWhenTagsFail(t *testing.T) {
+	f := chanutetest.New()
+	f.Support.AddCheck("Ti39halfu8", string(chanute.CheckAmazonRDSIdleDBInstances), "cost_optimizing", rdsIdleColumns,
+		[]string{"us-east-1", "orders", "No", "db.m5.large", "100", "14+", "$120"},
+	)
+	c := f.Clients()
+	c.RDS = &throttledRDS{RDS: f.RDS}
+
+	r, err := chanute.GenerateClientReport(c,
+		chanute.WithChecks(chanute.CheckAmazonRDSIdleDBInstances),
+		chanute.WithAggregationByTag("team"))
+	if err == nil {
+		t.Error("expected the tag lookup error")
+	}
+	if r == nil || r.CostOptimization == nil || r.CostOptimization.RDS == nil || len(r.CostOptimization.RDS.Instances) != 1 {
+		t.Fatalf("expected the instance to be reported, got %+v", r)
+	}
+	if i := r.CostOptimization.RDS.Instances[0]; i.Name != "orders" || i.EstimatedMonthlySavings != 120 || len(i.Tags) != 0 {
+		t.Errorf("expected orders without tags, got %+v", i)
+	}
+}
@@ -1,11 +1,11 @@
 package chanute
 
 import (
+	"context"
 	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/redshift"
 	"github.com/olekukonko/tablewriter"
 	"github.com/richardwilkes/toolbox/errs"
@@ -14,6 +14,7 @@ import (
 type RedshiftReport struct {
 	Clusters   []*RedShiftCluster
 	Aggregated []*RedshiftAggregate
+	Suppressed []*RedShiftCluster
 }
 
 type RedshiftAggregate struct {
@@ -22,17 +23,34 @@ type RedshiftAggregate struct {
 	Clusters                []*RedShiftCluster
 }
 
+// RedShiftCluster is a Redshift cluster that is underutilized
 type RedShiftCluster struct {
-	Type                    string
-	Reason                  string
-	EstimatedMonthlySavings int
-	Status                  string
-	Region                  string
-	Name                    string
+	FlaggedResource
+
+	Type                    string `ta:"Instance Type"`
+	Reason                  string `ta:"Reason"`
+	EstimatedMonthlySavings int    `ta:"Estimated Monthly Savings,dollars"`
+	Status                  string `ta:"Status"`
+	Region                  string `ta:"Region"`
+	Name                    string `ta:"Cluster"`
 	Tags                    map[string]string
 }
 
+func (c *RedShiftCluster) row() []string {
+	return []string{c.Name, c.Status, c.Reason, PrintDollars(c.EstimatedMonthlySavings)}
+}
+
+var redshiftHeaders = []string{"Name", "Status", "Reason", "Monthly Cost"}
+
 func (r *RedshiftReport) AsciiReport() string {
+	var suppressed [][]string
+	for _, c := range r.Suppressed {
+		suppressed = append(suppressed, c.row())
+	}
+	return withSuppressed(r.asciiReport(), "Redshift", redshiftHeaders, suppressed)
+}
+
+func (r *RedshiftReport) asciiReport() string {
 	if len(r.Clusters) == 0 {
 		return "Redshift: No issues"
 	}
@@ -40,11 +58,11 @@ func (r *RedshiftReport) AsciiReport() string {
 	o.WriteString("Redshift\n")
 
 	w := tablewriter.NewWriter(o)
-	w.SetHeader([]string{"Name", "Status", "Reason", "Monthly Cost"})
+	w.SetHeader(redshiftHeaders)
 
 	if r.Aggregated == nil {
 		for _, c := range r.Clusters {
-			w.Append([]string{c.Name, c.Status, c.Reason, PrintDollars(c.EstimatedMonthlySavings)})
+			w.Append(c.row())
 		}
 		w.Render()
 		return o.String()
@@ -55,7 +73,7 @@ func (r *RedshiftReport) AsciiReport() string {
 
 		if len(agg.Clusters) > 0 {
 			for _, c := range agg.Clusters {
-				w.Append([]string{c.Name, c.Status, c.Reason, PrintDollars(c.EstimatedMonthlySavings)})
+				w.Append(c.row())
 			}
 			w.Append([]string{"", "", "", ""})
 		}
@@ -65,46 +83,42 @@ func (r *RedshiftReport) AsciiReport() string {
 	return o.String()
 }
 
-func redshiftLowUtilization(config *Config, sess *session.Session, checks []*TrustedAdvisorCheck) (*RedshiftReport, error) {
-	m := checksToMaps(checks)
-
-	clusters := make(map[string]*RedShiftCluster, len(checks))
-	for _, instance := range m {
-		c := &RedShiftCluster{
-			Type:                    instance["Instance Type"],
-			Reason:                  instance["Reason"],
-			EstimatedMonthlySavings: parseAmount(instance["Estimated Monthly Savings"]),
-			Status:                  instance["Status"],
-			Region:                  instance["Region"],
-			Name:                    instance["Cluster"],
-		}
-		clusters[c.Name] = c
+func redshiftLowUtilization(ctx context.Context, config *Config, c *Clients, checks []*TrustedAdvisorCheck) (*RedshiftReport, error) {
+	r := &RedshiftReport{}
+	var decoded []*RedShiftCluster
+	err := decodeReportResources(config, checks, &decoded, &r.Suppressed)
+
+	clusters := make(map[string]*RedShiftCluster, len(decoded))
+	names := regionalIDs{}
+	for _, cluster := range decoded {
+		clusters[cluster.Region+"/"+cluster.Name] = cluster
+		names.add(cluster.Region, cluster.Name)
 	}
 
-	if config.GetTags {
-		allTags, err := GetRedshiftTags(sess)
-		if err != nil {
-			return nil, errs.Wrap(err)
+	if config.TagProvider != nil {
+		allTags, tagErr := tagsByRegion(ctx, c, config, TagKindRedshiftCluster, names)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
 		}
-		for id, tags := range allTags {
-			if c2, ok := clusters[id]; ok {
-				c2.Tags = tags
-			}
+		for _, cluster := range clusters {
+			cluster.Tags = allTags.Get(cluster.Region, cluster.Name)
 		}
 	}
-	r := &RedshiftReport{}
 	for _, c := range clusters {
 		r.Clusters = append(r.Clusters, c)
 	}
 
 	sort.Slice(r.Clusters, func(i, j int) bool {
-		return r.Clusters[i].EstimatedMonthlySavings > r.Clusters[j].EstimatedMonthlySavings
+		if r.Clusters[i].EstimatedMonthlySavings != r.Clusters[j].EstimatedMonthlySavings {
+			return r.Clusters[i].EstimatedMonthlySavings > r.Clusters[j].EstimatedMonthlySavings
+		}
+		return r.Clusters[i].Name < r.Clusters[j].Name
 	})
 
 	if config.Aggregator != nil {
 		aggregated := map[string]*RedshiftAggregate{}
 		for _, c := range r.Clusters {
-			key := config.Aggregator(c.Tags)
+			key := config.Aggregator(aggregationTags(c.Tags, c.Name, ""))
 			if key == "" {
 				key = c.Name
 			}
@@ -123,16 +137,22 @@ func redshiftLowUtilization(config *Config, sess *session.Session, checks []*Tru
 		}
 
 		sort.Slice(r.Aggregated, func(i, j int) bool {
-			return r.Aggregated[i].EstimatedMonthlySavings > r.Aggregated[j].EstimatedMonthlySavings
+			if r.Aggregated[i].EstimatedMonthlySavings != r.Aggregated[j].EstimatedMonthlySavings {
+				return r.Aggregated[i].EstimatedMonthlySavings > r.Aggregated[j].EstimatedMonthlySavings
+			}
+			return r.Aggregated[i].Key < r.Aggregated[j].Key
 		})
 	}
 
-	return r, nil
+	return r, err
 }
 
-func GetRedshiftTags(sess *session.Session) (TagMap, error) {
-	c := redshift.New(sess)
+// GetRedshiftTags is GetRedshiftTagsWithContext using a background context
+func GetRedshiftTags(c *Clients) (TagMap, error) {
+	return GetRedshiftTagsWithContext(context.Background(), c)
+}
 
+func GetRedshiftTagsWithContext(ctx context.Context, c *Clients) (TagMap, error) {
 	tags := map[string]map[string]string{}
 
 	// var ids []*string
@@ -145,7 +165,7 @@ func GetRedshiftTags(sess *session.Session) (TagMap, error) {
 	}
 
 	for {
-		page, err := c.DescribeClusters(input)
+		page, err := c.Redshift.DescribeClustersWithContext(ctx, input)
 		if err != nil {
 			errStr := err.Error()
 
@@ -205,9 +225,11 @@ func (r *RedshiftReport) AggregateRows(a Aggregator) []*AggregateRow {
 }
 func (r *RedShiftCluster) AggregateRow(a Aggregator) *AggregateRow {
 	return &AggregateRow{
-		Env:            "",
-		Service:        "EC2",
-		Key:            a(r.Tags),
+		Service:        "Redshift",
+		Region:         regionOf(r.Region),
+		ID:             r.Name,
+		Name:           r.Name,
+		Key:            a(aggregationTags(r.Tags, r.Name, "")),
 		MonthlySavings: r.EstimatedMonthlySavings,
 	}
 }
@@ -0,0 +1,267 @@
+package chanute
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// ReservedInstanceReport has the leases that are expiring, and the reserved instances Trusted Advisor recommends buying
+type ReservedInstanceReport struct {
+	Leases          []*ReservedInstanceLease
+	Recommendations []*ReservedInstanceRecommendation
+	// SuppressedLeases and SuppressedRecommendations are only set when using WithSuppressedResources
+	SuppressedLeases          []*ReservedInstanceLease
+	SuppressedRecommendations []*ReservedInstanceRecommendation
+}
+
+var reservedInstanceLeaseHeaders = []string{"Reserved Instance ID", "Zone", "Instance Type", "Count", "Expires", "Days to Expiry", "Estimated Monthly Savings"}
+var reservedInstanceRecommendationHeaders = []string{"Region", "Instance Type", "Platform", "Count", "Upfront Cost", "Estimated Monthly Savings", "Break Even (months)"}
+
+func (r *ReservedInstanceReport) AsciiReport() string {
+	var leases, recommendations [][]string
+	for _, l := range r.SuppressedLeases {
+		leases = append(leases, l.row())
+	}
+	for _, rec := range r.SuppressedRecommendations {
+		recommendations = append(recommendations, rec.row())
+	}
+	report := withSuppressed(r.asciiReport(), "Reserved Instance Leases", reservedInstanceLeaseHeaders, leases)
+	return withSuppressed(report, "Reserved Instance Recommendations", reservedInstanceRecommendationHeaders, recommendations)
+}
+
+func (r *ReservedInstanceReport) asciiReport() string {
+	if len(r.Leases) == 0 && len(r.Recommendations) == 0 {
+		return "Reserved Instances: No issues"
+	}
+
+	o := &strings.Builder{}
+	if len(r.Leases) > 0 {
+		o.WriteString("Reserved Instance Leases\n")
+		var rows [][]string
+		for _, l := range r.Leases {
+			rows = append(rows, l.row())
+		}
+		Table(o, reservedInstanceLeaseHeaders, rows)
+	}
+	if len(r.Recommendations) > 0 {
+		o.WriteString("Reserved Instance Recommendations\n")
+		var rows [][]string
+		for _, rec := range r.Recommendations {
+			rows = append(rows, rec.row())
+		}
+		Table(o, reservedInstanceRecommendationHeaders, rows)
+	}
+	return o.String()
+}
+
+func (r *ReservedInstanceReport) AggregateRows(a Aggregator) []*AggregateRow {
+	var o []*AggregateRow
+	for _, l := range r.Leases {
+		o = append(o, l.AggregateRow(a))
+	}
+	for _, rec := range r.Recommendations {
+		o = append(o, rec.AggregateRow(a))
+	}
+	return o
+}
+
+type ReservedInstanceLease struct {
+	FlaggedResource
+
+	Zone                    string `ta:"Zone"`
+	InstanceType            string `ta:"Instance Type"`
+	Platform                string `ta:"Platform"`
+	InstanceCount           int    `ta:"Instance Count"`
+	CurrentMonthlyCost      int    `ta:"Current Monthly Cost,dollars"`
+	EstimatedMonthlySavings int    `ta:"Estimated Monthly Savings,dollars"`
+	ExpirationDate          string `ta:"Expiration Date"`
+	ID                      string `ta:"Reserved Instance ID"`
+	Reason                  string `ta:"Reason"`
+
+	// Expires is parsed from ExpirationDate, and DaysToExpiry is counted from when Trusted Advisor last ran the check,
+	// so a report replayed from a recording is the same every time
+	Expires      time.Time
+	DaysToExpiry int
+
+	Tags map[string]string
+}
+
+func (l *ReservedInstanceLease) row() []string {
+	expires := l.ExpirationDate
+	if !l.Expires.IsZero() {
+		expires = l.Expires.Format("2006-01-02")
+	}
+	return []string{l.ID, l.Zone, l.InstanceType, strconv.Itoa(l.InstanceCount), expires, strconv.Itoa(l.DaysToExpiry), PrintDollars(l.EstimatedMonthlySavings)}
+}
+
+// AggregateRow counts the savings that are lost if the lease isn't renewed
+func (l *ReservedInstanceLease) AggregateRow(a Aggregator) *AggregateRow {
+	return &AggregateRow{
+		Service:        "EC2 Reserved Instances",
+		Region:         regionOf(l.Zone),
+		ID:             l.ID,
+		Name:           l.InstanceType,
+		Key:            a(aggregationTags(l.Tags, "", l.ID)),
+		MonthlySavings: l.EstimatedMonthlySavings,
+	}
+}
+
+// ReservedInstanceRecommendation isn't a resource, so it has no tags and is aggregated with untagged resources
+type ReservedInstanceRecommendation struct {
+	FlaggedResource
+
+	Region                  string  `ta:"Region"`
+	InstanceType            string  `ta:"Instance Type"`
+	Platform                string  `ta:"Platform"`
+	Count                   int     `ta:"Recommended Number of RIs to Purchase"`
+	UpfrontCost             int     `ta:"Upfront Cost of RIs,dollars"`
+	EstimatedMonthlySavings int     `ta:"Estimated Savings with Recommendation (monthly),dollars"`
+	BreakEvenMonths         float64 `ta:"Estimated Break Even (months)"`
+}
+
+func (rec *ReservedInstanceRecommendation) row() []string {
+	return []string{rec.Region, rec.InstanceType, rec.Platform, strconv.Itoa(rec.Count), PrintDollars(rec.UpfrontCost), PrintDollars(rec.EstimatedMonthlySavings), strconv.FormatFloat(rec.BreakEvenMonths, 'f', -1, 64)}
+}
+
+func (rec *ReservedInstanceRecommendation) AggregateRow(a Aggregator) *AggregateRow {
+	return &AggregateRow{
+		Service:        "EC2 Reserved Instances",
+		Region:         regionOf(rec.Region),
+		Name:           rec.InstanceType,
+		Key:            a(aggregationTags(nil, "", "")),
+		MonthlySavings: rec.EstimatedMonthlySavings,
+	}
+}
+
+// expirationLayouts are the formats Trusted Advisor has used for lease expiration dates
+var expirationLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+func parseExpiration(s string) (time.Time, error) {
+	var err error
+	for _, layout := range expirationLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// checkedAt is the latest time Trusted Advisor ran checks
+func checkedAt(checks []*TrustedAdvisorCheck) (time.Time, error) {
+	var latest time.Time
+	for _, ch := range checks {
+		if ch.Result == nil || ch.Result.Timestamp == nil {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, aws.StringValue(ch.Result.Timestamp))
+		if err != nil {
+			return time.Time{}, errs.NewWithCause("parsing the timestamp of "+ch.Name, err)
+		}
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	if latest.IsZero() {
+		return latest, errs.New("no timestamp to count days to expiry from")
+	}
+	return latest, nil
+}
+
+// reservedInstances builds one report from both reserved instance checks
+func reservedInstances(ctx context.Context, config *Config, c *Clients, lookups map[Check][]*TrustedAdvisorCheck) (*ReservedInstanceReport, error) {
+	r := &ReservedInstanceReport{}
+	var err error
+	if checks := lookups[CheckAmazonEC2ReservedInstanceLeaseExpiration]; len(checks) > 0 {
+		if decodeErr := decodeReportResources(config, checks, &r.Leases, &r.SuppressedLeases); decodeErr != nil {
+			err = errs.Append(err, decodeErr)
+		}
+	}
+	if checks := lookups[CheckAmazonEC2ReservedInstancesOptimization]; len(checks) > 0 {
+		if decodeErr := decodeReportResources(config, checks, &r.Recommendations, &r.SuppressedRecommendations); decodeErr != nil {
+			err = errs.Append(err, decodeErr)
+		}
+	}
+
+	checkedAt, timeErr := checkedAt(lookups[CheckAmazonEC2ReservedInstanceLeaseExpiration])
+	if timeErr != nil && len(r.Leases)+len(r.SuppressedLeases) > 0 {
+		err = errs.Append(err, timeErr)
+	}
+	for _, leases := range [][]*ReservedInstanceLease{r.Leases, r.SuppressedLeases} {
+		for _, l := range leases {
+			if l.ExpirationDate == "" {
+				continue
+			}
+			expires, parseErr := parseExpiration(l.ExpirationDate)
+			if parseErr != nil {
+				err = errs.Append(err, &ParseError{Check: string(CheckAmazonEC2ReservedInstanceLeaseExpiration), Column: "Expiration Date", Value: l.ExpirationDate, Err: parseErr})
+				continue
+			}
+			l.Expires = expires
+			if !checkedAt.IsZero() {
+				l.DaysToExpiry = int(expires.Sub(checkedAt).Hours() / 24)
+			}
+		}
+	}
+
+	if config.TagProvider != nil && len(r.Leases) > 0 {
+		ids := regionalIDs{}
+		for _, l := range r.Leases {
+			ids.add(l.Zone, l.ID)
+		}
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindReservedInstances, ids)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, l := range r.Leases {
+			l.Tags = tags.Get(l.Zone, l.ID)
+		}
+	}
+
+	sort.Slice(r.Leases, func(i, j int) bool {
+		if r.Leases[i].DaysToExpiry != r.Leases[j].DaysToExpiry {
+			return r.Leases[i].DaysToExpiry < r.Leases[j].DaysToExpiry
+		}
+		return r.Leases[i].ID < r.Leases[j].ID
+	})
+	sort.Slice(r.Recommendations, func(i, j int) bool {
+		a, b := r.Recommendations[i], r.Recommendations[j]
+		if a.EstimatedMonthlySavings != b.EstimatedMonthlySavings {
+			return a.EstimatedMonthlySavings > b.EstimatedMonthlySavings
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.InstanceType < b.InstanceType
+	})
+
+	return r, err
+}
+
+// GetReservedInstanceTags is GetReservedInstanceTagsWithContext using a background context
+func GetReservedInstanceTags(c *Clients, ids []*string) (TagMap, error) {
+	return GetReservedInstanceTagsWithContext(context.Background(), c, ids)
+}
+
+// GetReservedInstanceTagsWithContext filters by reserved-instances-id, so reservations that no longer exist are left out
+func GetReservedInstanceTagsWithContext(ctx context.Context, c *Clients, ids []*string) (TagMap, error) {
+	o, err := c.EC2.DescribeReservedInstancesWithContext(ctx, &ec2.DescribeReservedInstancesInput{
+		Filters: []*ec2.Filter{{Name: aws.String("reserved-instances-id"), Values: ids}},
+	})
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	tags := map[string]map[string]string{}
+	for _, ri := range o.ReservedInstances {
+		tags[aws.StringValue(ri.ReservedInstancesId)] = ec2TagsToMap(ri.Tags)
+	}
+	return tags, nil
+}
@@ -0,0 +1,57 @@
+package chanute_test
+
+import (
+	"testing"
+
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+var (
+	reservedInstanceLeaseColumns = []string{
+		"Status", "Zone", "Instance Type", "Platform", "Instance Count", "Current Monthly Cost",
+		"Estimated Monthly Savings", "Expiration Date", "Reserved Instance ID", "Reason",
+	}
+	reservedInstanceOptimizationColumns = []string{
+		"Region", "Instance Type", "Platform", "Recommended Number of RIs to Purchase", "Expected Average RI Utilization",
+		"Estimated Savings with Recommendation (monthly)", "Upfront Cost of RIs", "Estimated Cost of RIs (monthly)",
+		"Estimated Break Even (months)", "Lookback Period (days)", "Term (years)",
+	}
+)
+
+func TestReservedInstanceReport(t *testing.T) {
+	f := chanutetest.New()
+	// AddCheck's results are from 2020-01-01
+	f.Support.AddCheck("1e93e4c0b5", string(chanute.CheckAmazonEC2ReservedInstanceLeaseExpiration), "cost_optimizing", reservedInstanceLeaseColumns,
+		[]string{"Yellow", "us-east-1a", "m5.large", "Linux/UNIX", "2", "$100", "$40", "2020-01-31T00:00:00Z", "ri-1", "Expiring soon"},
+	)
+	f.Support.AddCheck("cX3c2R1chu", string(chanute.CheckAmazonEC2ReservedInstancesOptimization), "cost_optimizing", reservedInstanceOptimizationColumns,
+		[]string{"us-west-2", "c5.xlarge", "Linux/UNIX", "3", "90%", "$60", "$1,200", "$20", "20", "30", "1"},
+	)
+
+	r, err := chanute.GenerateClientReport(f.Clients(),
+		chanute.WithChecks(chanute.CheckAmazonEC2ReservedInstanceLeaseExpiration, chanute.CheckAmazonEC2ReservedInstancesOptimization),
+		chanute.WithAggregationByTag("team"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ri := r.CostOptimization.ReservedInstances
+	if len(ri.Leases) != 1 || len(ri.Recommendations) != 1 {
+		t.Fatalf("expected a lease and a recommendation, got %+v", ri)
+	}
+	if l := ri.Leases[0]; l.DaysToExpiry != 30 {
+		t.Errorf("expected 30 days to expiry, got %d", l.DaysToExpiry)
+	}
+	rec := ri.Recommendations[0]
+	if rec.Count != 3 || rec.UpfrontCost != 1200 || rec.EstimatedMonthlySavings != 60 || rec.BreakEvenMonths != 20 {
+		t.Errorf("recommendation decoded as %+v", rec)
+	}
+
+	var savings int
+	for _, row := range r.CostOptimization.AggregateRows(func(map[string]string) string { return "" }) {
+		savings += row.MonthlySavings
+	}
+	if savings != 100 {
+		t.Errorf("expected $100 of savings in the aggregate rows, got %d", savings)
+	}
+}
@@ -1,18 +1,28 @@
 package chanute
 
 import (
+	"context"
+	"sort"
 	"strings"
 
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/olekukonko/tablewriter"
 )
 
 type UnassociatedElasticIPAddressesReport struct {
 	IPs []*UnassociatedElasticIPAddresses
 	// Aggregated []*RedshiftAggregate
+	Suppressed []*UnassociatedElasticIPAddresses
 }
 
 func (r *UnassociatedElasticIPAddressesReport) AsciiReport() string {
+	var suppressed [][]string
+	for _, ip := range r.Suppressed {
+		suppressed = append(suppressed, []string{ip.IPAddress, ip.Region})
+	}
+	return withSuppressed(r.asciiReport(), "EIPs", []string{"IP", "Region"}, suppressed)
+}
+
+func (r *UnassociatedElasticIPAddressesReport) asciiReport() string {
 	if len(r.IPs) == 0 {
 		return "EIPs: No issues"
 	}
@@ -37,28 +47,31 @@ func (r *UnassociatedElasticIPAddressesReport) AggregateRows(a Aggregator) []*Ag
 }
 
 type UnassociatedElasticIPAddresses struct {
-	Region    string
-	IPAddress string
+	FlaggedResource
+
+	Region    string `ta:"Region"`
+	IPAddress string `ta:"IP Address"`
 }
 
 func (r *UnassociatedElasticIPAddresses) AggregateRow(a Aggregator) *AggregateRow {
 	return &AggregateRow{
 		Service:        "EIP",
+		Region:         regionOf(r.Region),
+		ID:             r.IPAddress,
+		Key:            a(aggregationTags(nil, "", r.IPAddress)),
 		MonthlySavings: 7,
 	}
 }
 
-func unassociatedElasticIPAddresses(cfg *Config, sess *session.Session, checks []*TrustedAdvisorCheck) (*UnassociatedElasticIPAddressesReport, error) {
+func unassociatedElasticIPAddresses(ctx context.Context, cfg *Config, c *Clients, checks []*TrustedAdvisorCheck) (*UnassociatedElasticIPAddressesReport, error) {
 	r := &UnassociatedElasticIPAddressesReport{}
-	m := checksToMaps(checks)
+	err := decodeReportResources(cfg, checks, &r.IPs, &r.Suppressed)
 
-	resources := make(map[string]*UnassociatedElasticIPAddresses, len(checks))
-	for _, lim := range m {
-		resource := &UnassociatedElasticIPAddresses{
-			Region:    lim["Region"],
-			IPAddress: lim["IP Address"],
+	sort.Slice(r.IPs, func(i, j int) bool {
+		if r.IPs[i].Region != r.IPs[j].Region {
+			return r.IPs[i].Region < r.IPs[j].Region
 		}
-		resources[resource.IPAddress] = resource
-	}
-	return r, nil
+		return r.IPs[i].IPAddress < r.IPs[j].IPAddress
+	})
+	return r, err
 }
@@ -0,0 +1,448 @@
+package chanute
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+type FaultToleranceReport struct {
+	RDSMultiAZ        []*RDSMultiAZ
+	RDSBackups        []*RDSBackups
+	EBSSnapshots      []*EBSSnapshots
+	AvailabilityZones []*AvailabilityZoneBalance
+	AutoScalingGroups []*AutoScalingGroupResource
+	CrossZoneELBs     []*ELBCrossZone
+
+	Aggregated []*FindingAggregate
+	Suppressed []*Finding
+}
+
+// faultToleranceReportChecks is the order checks are reported in
+var faultToleranceReportChecks = []Check{
+	CheckAmazonRDSMultiAZ,
+	CheckAmazonRDSBackups,
+	CheckAmazonEBSSnapshots,
+	CheckAmazonEC2AvailabilityZoneBalance,
+	CheckAutoScalingGroupResources,
+	CheckELBCrossZoneLoadBalancing,
+}
+
+// Findings are every flagged resource, in report order
+func (r *FaultToleranceReport) Findings() []*Finding {
+	var o []*Finding
+	for _, i := range r.RDSMultiAZ {
+		o = append(o, i.finding())
+	}
+	for _, i := range r.RDSBackups {
+		o = append(o, i.finding())
+	}
+	for _, s := range r.EBSSnapshots {
+		o = append(o, s.finding())
+	}
+	for _, z := range r.AvailabilityZones {
+		o = append(o, z.finding())
+	}
+	for _, g := range r.AutoScalingGroups {
+		o = append(o, g.finding())
+	}
+	for _, lb := range r.CrossZoneELBs {
+		o = append(o, lb.finding())
+	}
+	return o
+}
+
+func (r *FaultToleranceReport) AsciiReport() string {
+	return withSuppressedFindings(r.asciiReport(), "Fault Tolerance", r.Suppressed)
+}
+
+func (r *FaultToleranceReport) asciiReport() string {
+	findings := r.Findings()
+	if len(findings) == 0 {
+		return "Fault Tolerance: No issues"
+	}
+
+	o := &strings.Builder{}
+	o.WriteString("Fault Tolerance\n")
+	writeFindings(o, faultToleranceReportChecks, findings, r.Aggregated)
+	return o.String()
+}
+
+type RDSMultiAZ struct {
+	FlaggedResource
+
+	Region     string `ta:"Region"`
+	DBInstance string `ta:"DB Instance"`
+	VPCID      string `ta:"VPC ID"`
+	Reason     string `ta:"Reason"`
+
+	Tags map[string]string
+}
+
+func (i *RDSMultiAZ) finding() *Finding {
+	return &Finding{
+		Check:  CheckAmazonRDSMultiAZ,
+		Name:   i.DBInstance,
+		Region: i.Region,
+		Detail: i.Reason,
+		Tags:   i.Tags,
+	}
+}
+
+type RDSBackups struct {
+	FlaggedResource
+
+	Region          string `ta:"Region/AZ"`
+	DBInstance      string `ta:"DB Instance"`
+	VPCID           string `ta:"VPC ID"`
+	RetentionPeriod string `ta:"Backup Retention Period"`
+
+	Tags map[string]string
+}
+
+func (i *RDSBackups) finding() *Finding {
+	return &Finding{
+		Check:  CheckAmazonRDSBackups,
+		Name:   i.DBInstance,
+		Region: i.Region,
+		Detail: "backup retention period " + i.RetentionPeriod,
+		Tags:   i.Tags,
+	}
+}
+
+// EBSSnapshots is a volume without a recent snapshot, it's tagged with the volume's tags
+type EBSSnapshots struct {
+	FlaggedResource
+
+	Region       string `ta:"Region"`
+	VolumeID     string `ta:"Volume ID"`
+	VolumeName   string `ta:"Volume Name"`
+	SnapshotID   string `ta:"Snapshot ID"`
+	SnapshotName string `ta:"Snapshot Name"`
+	SnapshotAge  string `ta:"Snapshot Age"`
+	Attachment   string `ta:"Volume Attachment"`
+	Reason       string `ta:"Reason"`
+
+	Tags map[string]string
+}
+
+func (s *EBSSnapshots) finding() *Finding {
+	return &Finding{
+		Check:  CheckAmazonEBSSnapshots,
+		Name:   s.VolumeName,
+		ID:     s.VolumeID,
+		Region: s.Region,
+		Detail: s.Reason,
+		Tags:   s.Tags,
+	}
+}
+
+// AvailabilityZoneBalance is a region, so it has no tags
+type AvailabilityZoneBalance struct {
+	FlaggedResource
+
+	Region string `ta:"Region"`
+	Reason string `ta:"Reason"`
+}
+
+func (z *AvailabilityZoneBalance) finding() *Finding {
+	return &Finding{
+		Check:  CheckAmazonEC2AvailabilityZoneBalance,
+		Name:   z.Region,
+		Region: z.Region,
+		Detail: z.Reason,
+	}
+}
+
+type AutoScalingGroupResource struct {
+	FlaggedResource
+
+	Region       string `ta:"Region"`
+	Group        string `ta:"Auto Scaling Group Name"`
+	LaunchType   string `ta:"Launch Type"`
+	ResourceType string `ta:"Resource Type"`
+	ResourceName string `ta:"Resource Name"`
+
+	Tags map[string]string
+}
+
+func (g *AutoScalingGroupResource) finding() *Finding {
+	return &Finding{
+		Check:  CheckAutoScalingGroupResources,
+		Name:   g.Group,
+		ID:     g.ResourceName,
+		Region: g.Region,
+		Detail: g.LaunchType + " " + g.ResourceType,
+		Tags:   g.Tags,
+	}
+}
+
+type ELBCrossZone struct {
+	FlaggedResource
+
+	Region       string `ta:"Region"`
+	LoadBalancer string `ta:"Load Balancer Name"`
+	Reason       string `ta:"Reason"`
+
+	Tags map[string]string
+}
+
+func (lb *ELBCrossZone) finding() *Finding {
+	return &Finding{
+		Check:  CheckELBCrossZoneLoadBalancing,
+		Name:   lb.LoadBalancer,
+		Region: lb.Region,
+		Detail: lb.Reason,
+		Tags:   lb.Tags,
+	}
+}
+
+func faultToleranceReport(ctx context.Context, config *Config, c *Clients, lookups map[Check][]*TrustedAdvisorCheck) (*FaultToleranceReport, error) {
+	r := &FaultToleranceReport{}
+	var err error
+	for lookup, checks := range lookups {
+		var decodeErr error
+		switch lookup {
+		case CheckAmazonRDSMultiAZ:
+			var suppressed []*RDSMultiAZ
+			decodeErr = decodeReportResources(config, checks, &r.RDSMultiAZ, &suppressed)
+			for _, i := range suppressed {
+				r.Suppressed = append(r.Suppressed, i.finding())
+			}
+		case CheckAmazonRDSBackups:
+			var suppressed []*RDSBackups
+			decodeErr = decodeReportResources(config, checks, &r.RDSBackups, &suppressed)
+			for _, i := range suppressed {
+				r.Suppressed = append(r.Suppressed, i.finding())
+			}
+		case CheckAmazonEBSSnapshots:
+			var suppressed []*EBSSnapshots
+			decodeErr = decodeReportResources(config, checks, &r.EBSSnapshots, &suppressed)
+			for _, s := range suppressed {
+				r.Suppressed = append(r.Suppressed, s.finding())
+			}
+		case CheckAmazonEC2AvailabilityZoneBalance:
+			var suppressed []*AvailabilityZoneBalance
+			decodeErr = decodeReportResources(config, checks, &r.AvailabilityZones, &suppressed)
+			for _, z := range suppressed {
+				r.Suppressed = append(r.Suppressed, z.finding())
+			}
+		case CheckAutoScalingGroupResources:
+			var suppressed []*AutoScalingGroupResource
+			decodeErr = decodeReportResources(config, checks, &r.AutoScalingGroups, &suppressed)
+			for _, g := range suppressed {
+				r.Suppressed = append(r.Suppressed, g.finding())
+			}
+		case CheckELBCrossZoneLoadBalancing:
+			var suppressed []*ELBCrossZone
+			decodeErr = decodeReportResources(config, checks, &r.CrossZoneELBs, &suppressed)
+			for _, lb := range suppressed {
+				r.Suppressed = append(r.Suppressed, lb.finding())
+			}
+		}
+		if decodeErr != nil {
+			err = errs.Append(err, decodeErr)
+		}
+	}
+
+	if config.TagProvider != nil {
+		if tagErr := r.getTags(ctx, c, config); tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+	}
+
+	sort.Slice(r.RDSMultiAZ, func(i, j int) bool {
+		return r.RDSMultiAZ[i].DBInstance < r.RDSMultiAZ[j].DBInstance
+	})
+	sort.Slice(r.RDSBackups, func(i, j int) bool {
+		return r.RDSBackups[i].DBInstance < r.RDSBackups[j].DBInstance
+	})
+	sort.Slice(r.EBSSnapshots, func(i, j int) bool {
+		return r.EBSSnapshots[i].VolumeID < r.EBSSnapshots[j].VolumeID
+	})
+	sort.Slice(r.AvailabilityZones, func(i, j int) bool {
+		return r.AvailabilityZones[i].Region < r.AvailabilityZones[j].Region
+	})
+	sort.Slice(r.AutoScalingGroups, func(i, j int) bool {
+		a, b := r.AutoScalingGroups[i], r.AutoScalingGroups[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return a.ResourceName < b.ResourceName
+	})
+	sort.Slice(r.CrossZoneELBs, func(i, j int) bool {
+		return r.CrossZoneELBs[i].LoadBalancer < r.CrossZoneELBs[j].LoadBalancer
+	})
+	sortFindings(r.Suppressed)
+
+	if config.Aggregator != nil {
+		r.Aggregated = aggregateFindings(config, r.Findings())
+	}
+
+	return r, err
+}
+
+// getTags looks up the tags of DB instances, volumes, Auto Scaling groups and classic load balancers in their regions
+func (r *FaultToleranceReport) getTags(ctx context.Context, c *Clients, config *Config) error {
+	instances, volumes, groups, lbs := regionalIDs{}, regionalIDs{}, regionalIDs{}, regionalIDs{}
+	for _, i := range r.RDSMultiAZ {
+		instances.add(i.Region, i.DBInstance)
+	}
+	for _, i := range r.RDSBackups {
+		instances.add(i.Region, i.DBInstance)
+	}
+	for _, s := range r.EBSSnapshots {
+		volumes.add(s.Region, s.VolumeID)
+	}
+	for _, g := range r.AutoScalingGroups {
+		groups.add(g.Region, g.Group)
+	}
+	for _, lb := range r.CrossZoneELBs {
+		lbs.add(lb.Region, lb.LoadBalancer)
+	}
+
+	var err error
+	if len(instances) > 0 {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindRDSInstance, instances)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, i := range r.RDSMultiAZ {
+			i.Tags = tags.Get(i.Region, i.DBInstance)
+		}
+		for _, i := range r.RDSBackups {
+			i.Tags = tags.Get(i.Region, i.DBInstance)
+		}
+	}
+	if len(volumes) > 0 {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindEBSVolume, volumes)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, s := range r.EBSSnapshots {
+			s.Tags = tags.Get(s.Region, s.VolumeID)
+		}
+	}
+	if len(groups) > 0 {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindAutoScalingGroup, groups)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, g := range r.AutoScalingGroups {
+			g.Tags = tags.Get(g.Region, g.Group)
+		}
+	}
+	if len(lbs) > 0 {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindClassicLoadBalancer, lbs)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, lb := range r.CrossZoneELBs {
+			lb.Tags = tags.Get(lb.Region, lb.LoadBalancer)
+		}
+	}
+	return err
+}
+
+// GetAutoScalingGroupTags is GetAutoScalingGroupTagsWithContext using a background context
+func GetAutoScalingGroupTags(c *Clients, names []*string) (TagMap, error) {
+	return GetAutoScalingGroupTagsWithContext(context.Background(), c, names)
+}
+
+// GetAutoScalingGroupTagsWithContext filters by group name, so groups that no longer exist are left out
+func GetAutoScalingGroupTagsWithContext(ctx context.Context, c *Clients, names []*string) (TagMap, error) {
+	tags := map[string]map[string]string{}
+	var unique []*string
+	for name := range stringPtrSet(names) {
+		unique = append(unique, aws.String(name))
+	}
+	// sorted so the same names always make the same calls
+	sort.Slice(unique, func(i, j int) bool { return *unique[i] < *unique[j] })
+
+	// a filter can only have 5 values
+	for start := 0; start < len(unique); start += 5 {
+		end := start + 5
+		if end > len(unique) {
+			end = len(unique)
+		}
+		input := &autoscaling.DescribeTagsInput{
+			Filters: []*autoscaling.Filter{{Name: aws.String("auto-scaling-group"), Values: unique[start:end]}},
+		}
+		for {
+			page, err := c.AutoScaling.DescribeTagsWithContext(ctx, input)
+			if err != nil {
+				return nil, errs.Wrap(err)
+			}
+			for _, t := range page.Tags {
+				group := aws.StringValue(t.ResourceId)
+				if tags[group] == nil {
+					tags[group] = map[string]string{}
+				}
+				tags[group][aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+			}
+
+			if page.NextToken == nil {
+				break
+			}
+			input.NextToken = page.NextToken
+		}
+	}
+	return tags, nil
+}
+
+// GetClassicLBTags is GetClassicLBTagsWithContext using a background context
+func GetClassicLBTags(c *Clients, names []*string) (TagMap, error) {
+	return GetClassicLBTagsWithContext(context.Background(), c, names)
+}
+
+// GetClassicLBTagsWithContext leaves out load balancers that no longer exist
+func GetClassicLBTagsWithContext(ctx context.Context, c *Clients, names []*string) (TagMap, error) {
+	tags := map[string]map[string]string{}
+	var unique []*string
+	for name := range stringPtrSet(names) {
+		unique = append(unique, aws.String(name))
+	}
+	// sorted so the same names always make the same calls
+	sort.Slice(unique, func(i, j int) bool { return *unique[i] < *unique[j] })
+
+	// DescribeTags takes up to 20 names, and fails if any of them don't exist
+	for start := 0; start < len(unique); start += 20 {
+		end := start + 20
+		if end > len(unique) {
+			end = len(unique)
+		}
+		batch := [][]*string{unique[start:end]}
+		for len(batch) > 0 {
+			input := &elb.DescribeTagsInput{LoadBalancerNames: batch[0]}
+			batch = batch[1:]
+
+			o, err := c.ELB.DescribeTagsWithContext(ctx, input)
+			if err != nil {
+				if ae, ok := err.(awserr.Error); ok && ae.Code() == elb.ErrCodeAccessPointNotFoundException {
+					// retry one at a time to find the ones that exist
+					if len(input.LoadBalancerNames) > 1 {
+						for _, n := range input.LoadBalancerNames {
+							batch = append(batch, []*string{n})
+						}
+					}
+					continue
+				}
+				return nil, errs.Wrap(err)
+			}
+			for _, d := range o.TagDescriptions {
+				lbTags := map[string]string{}
+				for _, t := range d.Tags {
+					lbTags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+				}
+				tags[aws.StringValue(d.LoadBalancerName)] = lbTags
+			}
+		}
+	}
+	return tags, nil
+}
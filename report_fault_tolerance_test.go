@@ -0,0 +1,93 @@
+package chanute_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+func faultToleranceFakes() *chanutetest.Fakes {
+	f := chanutetest.New()
+	f.Support.AddCheck("f2iK5R6Dep", string(chanute.CheckAmazonRDSMultiAZ), "fault_tolerance",
+		[]string{"Region", "DB Instance", "VPC ID", "Reason"},
+		[]string{"us-east-1", "orders", "vpc-1", "Single-AZ"},
+	)
+	f.Support.AddCheck("8CNsSllI5v", string(chanute.CheckAutoScalingGroupResources), "fault_tolerance",
+		[]string{"Region", "Auto Scaling Group Name", "Launch Type", "Resource Type", "Resource Name"},
+		[]string{"us-east-1", "web-asg", "Launch Configuration", "AMI", "ami-1"},
+	)
+	elbs := f.Support.AddCheck("xdeXZKIUy", string(chanute.CheckELBCrossZoneLoadBalancing), "fault_tolerance",
+		[]string{"Region", "Load Balancer Name", "Reason"},
+		[]string{"us-west-2", "west-lb", "Cross-zone load balancing disabled"},
+		[]string{"us-east-1", "old-lb", "Cross-zone load balancing disabled"},
+	)
+	elbs.FlaggedResources[1].IsSuppressed = aws.Bool(true)
+
+	f.RDS.AddInstance("arn:aws:rds:us-east-1:123456789012:db:orders", map[string]string{"team": "data"})
+	f.AutoScaling.AddGroup("web-asg", map[string]string{"team": "web"})
+	f.InRegion("us-west-2").ELB.AddLoadBalancer("west-lb", map[string]string{"team": "web"})
+	return f
+}
+
+var faultToleranceChecks = chanute.WithChecks(chanute.CheckAmazonRDSMultiAZ, chanute.CheckAutoScalingGroupResources, chanute.CheckELBCrossZoneLoadBalancing)
+
+func TestFaultToleranceReport(t *testing.T) {
+	r, err := chanute.GenerateClientReport(faultToleranceFakes().Clients(), faultToleranceChecks, chanute.WithSuppressedResources())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ft := r.FaultTolerance
+	if ft == nil {
+		t.Fatal("expected a fault tolerance report")
+	}
+	if len(ft.RDSMultiAZ) != 1 || ft.RDSMultiAZ[0].DBInstance != "orders" || ft.RDSMultiAZ[0].VPCID != "vpc-1" {
+		t.Errorf("RDS Multi-AZ is %+v", ft.RDSMultiAZ)
+	}
+	if len(ft.AutoScalingGroups) != 1 || ft.AutoScalingGroups[0].Group != "web-asg" || ft.AutoScalingGroups[0].ResourceName != "ami-1" {
+		t.Errorf("Auto Scaling groups are %+v", ft.AutoScalingGroups)
+	}
+	if len(ft.CrossZoneELBs) != 1 || ft.CrossZoneELBs[0].LoadBalancer != "west-lb" {
+		t.Errorf("cross-zone load balancers are %+v", ft.CrossZoneELBs)
+	}
+	if len(ft.Suppressed) != 1 || ft.Suppressed[0].Name != "old-lb" {
+		t.Errorf("suppressed are %+v", ft.Suppressed)
+	}
+
+	report := ft.AsciiReport()
+	// checks are rendered in report order, with the suppressed resources last
+	var last int
+	for _, s := range []string{"Fault Tolerance\n", string(chanute.CheckAmazonRDSMultiAZ), "Single-AZ",
+		string(chanute.CheckAutoScalingGroupResources), "Launch Configuration AMI",
+		string(chanute.CheckELBCrossZoneLoadBalancing), "west-lb", "old-lb"} {
+		i := strings.Index(report[last:], s)
+		if i < 0 {
+			t.Fatalf("expected %q after position %d of the report:\n%s", s, last, report)
+		}
+		last += i
+	}
+}
+
+func TestFaultToleranceReportAggregated(t *testing.T) {
+	r, err := chanute.GenerateClientReport(faultToleranceFakes().Clients(), faultToleranceChecks, chanute.WithAggregationByTag("team"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ft := r.FaultTolerance
+	if ft == nil {
+		t.Fatal("expected a fault tolerance report")
+	}
+	counts := map[string]int{}
+	for _, agg := range ft.Aggregated {
+		counts[agg.Key] = agg.Count
+	}
+	// the load balancer's tags are looked up in its own region
+	if len(counts) != 2 || counts["web"] != 2 || counts["data"] != 1 {
+		t.Errorf("aggregated counts are %v", counts)
+	}
+	if report := ft.AsciiReport(); !strings.Contains(report, "2 findings") || !strings.Contains(report, "west-lb") {
+		t.Errorf("expected the aggregated findings in the report:\n%s", report)
+	}
+}
@@ -0,0 +1,178 @@
+package chanute
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// GenericCheckReport is any check without a typed report, with the check's own columns.
+// Columns are in the configured language, use the English description to decode them by name.
+type GenericCheckReport struct {
+	Check    Check
+	ID       string
+	Category string
+	// Status is the status of the check, ok, warning, error or not_available
+	Status     string
+	Columns    []string
+	Resources  []*GenericRow
+	Suppressed []*GenericRow
+
+	// statusColumn and regionColumn are set when the check's columns don't already have them
+	statusColumn, regionColumn bool
+}
+
+// GenericRow is a flagged resource, Values line up with GenericCheckReport.Columns
+type GenericRow struct {
+	FlaggedResource
+
+	Region     string
+	ResourceID string
+	Values     []string
+}
+
+// typedChecks have their own report, every other selected check gets a GenericCheckReport
+var typedChecks = func() map[Check]bool {
+	typed := map[Check]bool{CheckMFAonRootAccount: true}
+	for _, checks := range [][]Check{
+		costReportChecks,
+		serviceLimitChecks,
+		securityReportChecks,
+		faultToleranceReportChecks,
+		performanceReportChecks,
+	} {
+		for _, c := range checks {
+			typed[c] = true
+		}
+	}
+	return typed
+}()
+
+func (r *GenericCheckReport) Title() string {
+	return string(r.Check)
+}
+
+func (r *GenericCheckReport) Headers() []string {
+	var o []string
+	if r.statusColumn {
+		o = append(o, "Status")
+	}
+	if r.regionColumn {
+		o = append(o, "Region")
+	}
+	return append(o, r.Columns...)
+}
+
+func (r *GenericCheckReport) Rows() [][]string {
+	var o [][]string
+	for _, row := range r.Resources {
+		o = append(o, r.row(row))
+	}
+	return o
+}
+
+func (r *GenericCheckReport) row(row *GenericRow) []string {
+	var o []string
+	if r.statusColumn {
+		o = append(o, row.Status)
+	}
+	if r.regionColumn {
+		o = append(o, row.Region)
+	}
+	return append(o, row.Values...)
+}
+
+func (r *GenericCheckReport) AsciiReport() string {
+	var suppressed [][]string
+	for _, row := range r.Suppressed {
+		suppressed = append(suppressed, r.row(row))
+	}
+	return withSuppressed(r.asciiReport(), r.Title(), r.Headers(), suppressed)
+}
+
+func (r *GenericCheckReport) asciiReport() string {
+	if len(r.Resources) == 0 {
+		return r.Title() + ": No issues"
+	}
+	o := &strings.Builder{}
+	o.WriteString(r.Title() + " (" + r.Status + ")\n")
+	Table(o, r.Headers(), r.Rows())
+	return o.String()
+}
+
+// WriteCSV writes the headers and rows, without suppressed resources
+func (r *GenericCheckReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.Headers()); err != nil {
+		return errs.Wrap(err)
+	}
+	if err := cw.WriteAll(r.Rows()); err != nil {
+		return errs.Wrap(err)
+	}
+	return nil
+}
+
+// genericCheckReport merges the results of a check, in case Trusted Advisor returned it more than once
+func genericCheckReport(config *Config, check Check, checks []*TrustedAdvisorCheck) *GenericCheckReport {
+	r := &GenericCheckReport{Check: check, statusColumn: true, regionColumn: true}
+	for _, ch := range checks {
+		r.ID = ch.ID
+		r.Category = ch.Category
+		r.Status = ch.Status
+		if ch.Check != nil && r.Columns == nil {
+			r.Columns = aws.StringValueSlice(ch.Check.Metadata)
+			columns := columnIndex(ch)
+			_, hasStatus := columns["status"]
+			_, hasRegion := columns["region"]
+			r.statusColumn, r.regionColumn = !hasStatus, !hasRegion
+		}
+		if ch.Result == nil {
+			continue
+		}
+
+		for _, res := range ch.Result.FlaggedResources {
+			row := &GenericRow{
+				FlaggedResource: FlaggedResource{
+					Status:       aws.StringValue(res.Status),
+					IsSuppressed: aws.BoolValue(res.IsSuppressed),
+				},
+				Region:     aws.StringValue(res.Region),
+				ResourceID: aws.StringValue(res.ResourceId),
+				Values:     aws.StringValueSlice(res.Metadata),
+			}
+			switch {
+			case !row.IsSuppressed:
+				r.Resources = append(r.Resources, row)
+			case config.IncludeSuppressed:
+				r.Suppressed = append(r.Suppressed, row)
+			}
+		}
+	}
+
+	for _, rows := range [][]*GenericRow{r.Resources, r.Suppressed} {
+		rows := rows
+		sort.SliceStable(rows, func(i, j int) bool {
+			if rows[i].Region != rows[j].Region {
+				return rows[i].Region < rows[j].Region
+			}
+			return rows[i].ResourceID < rows[j].ResourceID
+		})
+	}
+	return r
+}
+
+// sortedGenericChecks are the generic reports in check order
+func sortedGenericChecks(reports map[Check]*GenericCheckReport) []*GenericCheckReport {
+	var o []*GenericCheckReport
+	for _, r := range reports {
+		o = append(o, r)
+	}
+	sort.Slice(o, func(i, j int) bool {
+		return o[i].Check < o[j].Check
+	})
+	return o
+}
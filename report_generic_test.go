@@ -0,0 +1,97 @@
+package chanute_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+func cloudTrailFakes() *chanutetest.Fakes {
+	f := chanutetest.New()
+	res := f.Support.AddCheck("vjafUGJ9H0", string(chanute.CheckAWSCloudTrailLogging), "security",
+		[]string{"Region", "Trail Name", "Logging Status", "Bucket Name", "Last Delivery Error"},
+		[]string{"us-west-2", "audit", "Off", "audit-logs", ""},
+		[]string{"us-east-1", "main", "On", "main-logs", "AccessDenied"},
+		[]string{"us-east-1", "old", "Off", "old-logs", ""},
+	)
+	for i, region := range []string{"us-west-2", "us-east-1", "us-east-1"} {
+		res.FlaggedResources[i].Region = aws.String(region)
+		res.FlaggedResources[i].ResourceId = res.FlaggedResources[i].Metadata[1]
+	}
+	res.FlaggedResources[0].Status = aws.String("error")
+	res.FlaggedResources[2].IsSuppressed = aws.Bool(true)
+	return f
+}
+
+func TestGenericCheckReport(t *testing.T) {
+	r, err := chanute.GenerateClientReport(cloudTrailFakes().Clients(), chanute.WithChecks(chanute.CheckAWSCloudTrailLogging))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := r.GenericChecks[chanute.CheckAWSCloudTrailLogging]
+	if g == nil {
+		t.Fatalf("expected a generic report, got %v", r.GenericChecks)
+	}
+	if g.ID != "vjafUGJ9H0" || g.Category != "security" || g.Status != "warning" {
+		t.Errorf("check is %s %s %s", g.ID, g.Category, g.Status)
+	}
+
+	// the check has a Region column, so only Status is added
+	wantHeaders := []string{"Status", "Region", "Trail Name", "Logging Status", "Bucket Name", "Last Delivery Error"}
+	if got := g.Headers(); !reflect.DeepEqual(got, wantHeaders) {
+		t.Errorf("headers are %q, expected %q", got, wantHeaders)
+	}
+	// rows are sorted by region, and the suppressed trail is left out
+	wantRows := [][]string{
+		{"warning", "us-east-1", "main", "On", "main-logs", "AccessDenied"},
+		{"error", "us-west-2", "audit", "Off", "audit-logs", ""},
+	}
+	if got := g.Rows(); !reflect.DeepEqual(got, wantRows) {
+		t.Errorf("rows are %q, expected %q", got, wantRows)
+	}
+	if len(g.Suppressed) != 0 {
+		t.Errorf("expected no suppressed rows, got %+v", g.Suppressed)
+	}
+
+	var csv bytes.Buffer
+	if err := g.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	wantCSV := "Status,Region,Trail Name,Logging Status,Bucket Name,Last Delivery Error\n" +
+		"warning,us-east-1,main,On,main-logs,AccessDenied\n" +
+		"error,us-west-2,audit,Off,audit-logs,\n"
+	if csv.String() != wantCSV {
+		t.Errorf("CSV is\n%s\nexpected\n%s", csv.String(), wantCSV)
+	}
+	if report := g.AsciiReport(); !strings.HasPrefix(report, string(chanute.CheckAWSCloudTrailLogging)+" (warning)\n") || strings.Contains(report, "old-logs") {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+}
+
+func TestGenericCheckReportSuppressed(t *testing.T) {
+	r, err := chanute.GenerateClientReport(cloudTrailFakes().Clients(),
+		chanute.WithChecks(chanute.CheckAWSCloudTrailLogging), chanute.WithSuppressedResources())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := r.GenericChecks[chanute.CheckAWSCloudTrailLogging]
+	if g == nil {
+		t.Fatalf("expected a generic report, got %v", r.GenericChecks)
+	}
+	if len(g.Rows()) != 2 {
+		t.Errorf("expected the suppressed trail to stay out of the rows, got %q", g.Rows())
+	}
+	if len(g.Suppressed) != 1 || g.Suppressed[0].ResourceID != "old" || !g.Suppressed[0].IsSuppressed {
+		t.Fatalf("suppressed are %+v", g.Suppressed)
+	}
+	report := g.AsciiReport()
+	suppressed := strings.Index(report, string(chanute.CheckAWSCloudTrailLogging)+" (suppressed)\n")
+	if suppressed < 0 || !strings.Contains(report[suppressed:], "old-logs") || strings.Contains(report[:suppressed], "old-logs") {
+		t.Errorf("expected the suppressed trail in its own section:\n%s", report)
+	}
+}
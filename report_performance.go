@@ -0,0 +1,311 @@
+package chanute
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+type PerformanceReport struct {
+	HighUtilization    []*HighUtilizationEC2Instance
+	SecurityGroupRules []*SecurityGroupRules
+	EBSThroughput      []*EBSThroughputInstance
+	MagneticVolumes    []*OverutilizedMagneticVolume
+
+	Aggregated []*FindingAggregate
+	Suppressed []*Finding
+}
+
+// performanceReportChecks is the order checks are reported in
+var performanceReportChecks = []Check{
+	CheckHighUtilizationAmazonEC2Instances,
+	CheckAmazonEC2toEBSThroughputOptimization,
+	CheckOverutilizedAmazonEBSMagneticVolumes,
+	CheckLargeNumberofRulesinanEC2SecurityGroup,
+}
+
+// Findings are every flagged resource, in report order
+func (r *PerformanceReport) Findings() []*Finding {
+	var o []*Finding
+	for _, i := range r.HighUtilization {
+		o = append(o, i.finding())
+	}
+	for _, i := range r.EBSThroughput {
+		o = append(o, i.finding())
+	}
+	for _, v := range r.MagneticVolumes {
+		o = append(o, v.finding())
+	}
+	for _, g := range r.SecurityGroupRules {
+		o = append(o, g.finding())
+	}
+	return o
+}
+
+func (r *PerformanceReport) AsciiReport() string {
+	return withSuppressedFindings(r.asciiReport(), "Performance", r.Suppressed)
+}
+
+func (r *PerformanceReport) asciiReport() string {
+	findings := r.Findings()
+	if len(findings) == 0 {
+		return "Performance: No issues"
+	}
+
+	o := &strings.Builder{}
+	o.WriteString("Performance\n")
+	writeFindings(o, performanceReportChecks, findings, r.Aggregated)
+	return o.String()
+}
+
+// HighUtilizationEC2Instance is the opposite of EC2Instance, Day1 to Day14 are the daily CPU utilization
+type HighUtilizationEC2Instance struct {
+	FlaggedResource
+
+	RegionAZ string `ta:"Region/AZ"`
+	ID       string `ta:"Instance ID"`
+	Name     string `ta:"Instance Name"`
+	Type     string `ta:"Instance Type"`
+
+	CPU14DayAverage  float64 `ta:"14-Day Average CPU Utilization,percent"`
+	HighUtilizedDays int     `ta:"Number of Days over 90% CPU Utilization,days"`
+
+	Day1  float64 `ta:"Day 1,percent"`
+	Day2  float64 `ta:"Day 2,percent"`
+	Day3  float64 `ta:"Day 3,percent"`
+	Day4  float64 `ta:"Day 4,percent"`
+	Day5  float64 `ta:"Day 5,percent"`
+	Day6  float64 `ta:"Day 6,percent"`
+	Day7  float64 `ta:"Day 7,percent"`
+	Day8  float64 `ta:"Day 8,percent"`
+	Day9  float64 `ta:"Day 9,percent"`
+	Day10 float64 `ta:"Day 10,percent"`
+	Day11 float64 `ta:"Day 11,percent"`
+	Day12 float64 `ta:"Day 12,percent"`
+	Day13 float64 `ta:"Day 13,percent"`
+	Day14 float64 `ta:"Day 14,percent"`
+
+	Tags map[string]string
+}
+
+// DailyCPU is the CPU utilization for each of the 14 days, oldest first
+func (i *HighUtilizationEC2Instance) DailyCPU() []float64 {
+	return []float64{
+		i.Day1, i.Day2, i.Day3, i.Day4, i.Day5, i.Day6, i.Day7,
+		i.Day8, i.Day9, i.Day10, i.Day11, i.Day12, i.Day13, i.Day14,
+	}
+}
+
+func (i *HighUtilizationEC2Instance) finding() *Finding {
+	return &Finding{
+		Check:  CheckHighUtilizationAmazonEC2Instances,
+		Name:   i.Name,
+		ID:     i.ID,
+		Region: i.RegionAZ,
+		Detail: fmt.Sprintf("%s averaging %.1f%% CPU, %d days over 90%%", i.Type, i.CPU14DayAverage, i.HighUtilizedDays),
+		Tags:   i.Tags,
+	}
+}
+
+type SecurityGroupRules struct {
+	FlaggedResource
+
+	Region        string `ta:"Region"`
+	Name          string `ta:"Security Group Name"`
+	ID            string `ta:"Group ID"`
+	Description   string `ta:"Description"`
+	InstanceCount int    `ta:"Instance Count"`
+	VPCID         string `ta:"VPC ID"`
+	InboundRules  int    `ta:"Total Inbound Rules"`
+	OutboundRules int    `ta:"Total Outbound Rules"`
+
+	Tags map[string]string
+}
+
+func (g *SecurityGroupRules) finding() *Finding {
+	return &Finding{
+		Check:  CheckLargeNumberofRulesinanEC2SecurityGroup,
+		Name:   g.Name,
+		ID:     g.ID,
+		Region: g.Region,
+		Detail: strconv.Itoa(g.InboundRules) + " inbound, " + strconv.Itoa(g.OutboundRules) + " outbound rules",
+		Tags:   g.Tags,
+	}
+}
+
+// EBSThroughputInstance is an instance whose EBS throughput was near the maximum for its type
+type EBSThroughputInstance struct {
+	FlaggedResource
+
+	Region          string `ta:"Region"`
+	ID              string `ta:"Instance ID"`
+	Type            string `ta:"Instance Type"`
+	TimeNearMaximum string `ta:"Time Near Maximum"`
+
+	Tags map[string]string
+}
+
+func (i *EBSThroughputInstance) finding() *Finding {
+	return &Finding{
+		Check:  CheckAmazonEC2toEBSThroughputOptimization,
+		ID:     i.ID,
+		Region: i.Region,
+		Detail: i.Type + " near maximum EBS throughput " + i.TimeNearMaximum + " of the time",
+		Tags:   i.Tags,
+	}
+}
+
+type OverutilizedMagneticVolume struct {
+	FlaggedResource
+
+	Region         string `ta:"Region"`
+	ID             string `ta:"Volume ID"`
+	Name           string `ta:"Volume Name"`
+	DaysOver       int    `ta:"Number of Days Over,days"`
+	MaxDailyMedian string `ta:"Max Daily Median"`
+
+	Tags map[string]string
+}
+
+func (v *OverutilizedMagneticVolume) finding() *Finding {
+	return &Finding{
+		Check:  CheckOverutilizedAmazonEBSMagneticVolumes,
+		Name:   v.Name,
+		ID:     v.ID,
+		Region: v.Region,
+		Detail: strconv.Itoa(v.DaysOver) + " days over, max daily median " + v.MaxDailyMedian,
+		Tags:   v.Tags,
+	}
+}
+
+func performanceReport(ctx context.Context, config *Config, c *Clients, lookups map[Check][]*TrustedAdvisorCheck) (*PerformanceReport, error) {
+	r := &PerformanceReport{}
+	var err error
+	for lookup, checks := range lookups {
+		var decodeErr error
+		switch lookup {
+		case CheckHighUtilizationAmazonEC2Instances:
+			var suppressed []*HighUtilizationEC2Instance
+			decodeErr = decodeReportResources(config, checks, &r.HighUtilization, &suppressed)
+			for _, i := range suppressed {
+				r.Suppressed = append(r.Suppressed, i.finding())
+			}
+		case CheckLargeNumberofRulesinanEC2SecurityGroup:
+			var suppressed []*SecurityGroupRules
+			decodeErr = decodeReportResources(config, checks, &r.SecurityGroupRules, &suppressed)
+			for _, g := range suppressed {
+				r.Suppressed = append(r.Suppressed, g.finding())
+			}
+		case CheckAmazonEC2toEBSThroughputOptimization:
+			var suppressed []*EBSThroughputInstance
+			decodeErr = decodeReportResources(config, checks, &r.EBSThroughput, &suppressed)
+			for _, i := range suppressed {
+				r.Suppressed = append(r.Suppressed, i.finding())
+			}
+		case CheckOverutilizedAmazonEBSMagneticVolumes:
+			var suppressed []*OverutilizedMagneticVolume
+			decodeErr = decodeReportResources(config, checks, &r.MagneticVolumes, &suppressed)
+			for _, v := range suppressed {
+				r.Suppressed = append(r.Suppressed, v.finding())
+			}
+		}
+		if decodeErr != nil {
+			err = errs.Append(err, decodeErr)
+		}
+	}
+
+	if config.TagProvider != nil {
+		if tagErr := r.getTags(ctx, c, config); tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+	}
+
+	sort.Slice(r.HighUtilization, func(i, j int) bool {
+		a, b := r.HighUtilization[i], r.HighUtilization[j]
+		if a.HighUtilizedDays != b.HighUtilizedDays {
+			return a.HighUtilizedDays > b.HighUtilizedDays
+		}
+		if a.CPU14DayAverage != b.CPU14DayAverage {
+			return a.CPU14DayAverage > b.CPU14DayAverage
+		}
+		return a.ID < b.ID
+	})
+	sort.Slice(r.SecurityGroupRules, func(i, j int) bool {
+		a, b := r.SecurityGroupRules[i], r.SecurityGroupRules[j]
+		if a.InboundRules+a.OutboundRules != b.InboundRules+b.OutboundRules {
+			return a.InboundRules+a.OutboundRules > b.InboundRules+b.OutboundRules
+		}
+		return a.ID < b.ID
+	})
+	sort.Slice(r.EBSThroughput, func(i, j int) bool {
+		return r.EBSThroughput[i].ID < r.EBSThroughput[j].ID
+	})
+	sort.Slice(r.MagneticVolumes, func(i, j int) bool {
+		a, b := r.MagneticVolumes[i], r.MagneticVolumes[j]
+		if a.DaysOver != b.DaysOver {
+			return a.DaysOver > b.DaysOver
+		}
+		return a.ID < b.ID
+	})
+	sortFindings(r.Suppressed)
+
+	if config.Aggregator != nil {
+		r.Aggregated = aggregateFindings(config, r.Findings())
+	}
+
+	return r, err
+}
+
+// getTags looks up the tags of instances, security groups and volumes in their regions
+func (r *PerformanceReport) getTags(ctx context.Context, c *Clients, config *Config) error {
+	instances, groupIDs, volumes := regionalIDs{}, regionalIDs{}, regionalIDs{}
+	for _, i := range r.HighUtilization {
+		instances.add(i.RegionAZ, i.ID)
+	}
+	for _, i := range r.EBSThroughput {
+		instances.add(i.Region, i.ID)
+	}
+	for _, g := range r.SecurityGroupRules {
+		groupIDs.add(g.Region, g.ID)
+	}
+	for _, v := range r.MagneticVolumes {
+		volumes.add(v.Region, v.ID)
+	}
+
+	var err error
+	if len(instances) > 0 {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindEC2Instance, instances)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, i := range r.HighUtilization {
+			i.Tags = tags.Get(i.RegionAZ, i.ID)
+		}
+		for _, i := range r.EBSThroughput {
+			i.Tags = tags.Get(i.Region, i.ID)
+		}
+	}
+	if len(groupIDs) > 0 {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindSecurityGroup, groupIDs)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, g := range r.SecurityGroupRules {
+			g.Tags = tags.Get(g.Region, g.ID)
+		}
+	}
+	if len(volumes) > 0 {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindEBSVolume, volumes)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, v := range r.MagneticVolumes {
+			v.Tags = tags.Get(v.Region, v.ID)
+		}
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package chanute_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+func performanceFakes() *chanutetest.Fakes {
+	f := chanutetest.New()
+	f.Support.AddCheck("ZRxQlPsb6c", string(chanute.CheckHighUtilizationAmazonEC2Instances), "performance",
+		append([]string{"Region/AZ", "Instance ID", "Instance Name", "Instance Type", "14-Day Average CPU Utilization", "Number of Days over 90% CPU Utilization"}, dayColumns...),
+		[]string{"us-east-1a", "i-1", "batch-1", "c5.large", "91.5%", "4 days", "90%", "95%"},
+		[]string{"us-east-1b", "i-2", "batch-2", "c5.large", "97%", "10 days"},
+	)
+	f.Support.AddCheck("Bh2xRR2FGH", string(chanute.CheckAmazonEC2toEBSThroughputOptimization), "performance",
+		[]string{"Region", "Instance ID", "Instance Type", "Time Near Maximum"},
+		[]string{"us-east-1", "i-3", "m5.large", "15%"},
+	)
+	f.Support.AddCheck("tfg86AVHAZ", string(chanute.CheckLargeNumberofRulesinanEC2SecurityGroup), "performance",
+		[]string{"Region", "Security Group Name", "Group ID", "Description", "Instance Count", "VPC ID", "Total Inbound Rules", "Total Outbound Rules"},
+		[]string{"us-east-1", "web", "sg-1", "web servers", "12", "vpc-1", "120", "2"},
+	)
+
+	f.EC2.AddInstance("i-1", map[string]string{"team": "batch"})
+	f.EC2.AddInstance("i-2", map[string]string{"team": "batch"})
+	f.EC2.AddInstance("i-3", map[string]string{"team": "web"})
+	f.EC2.AddSecurityGroup("sg-1", map[string]string{"team": "web"})
+	return f
+}
+
+var performanceChecks = chanute.WithChecks(chanute.CheckHighUtilizationAmazonEC2Instances,
+	chanute.CheckAmazonEC2toEBSThroughputOptimization, chanute.CheckLargeNumberofRulesinanEC2SecurityGroup)
+
+func TestPerformanceReport(t *testing.T) {
+	r, err := chanute.GenerateClientReport(performanceFakes().Clients(), performanceChecks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := r.Performance
+	if p == nil {
+		t.Fatal("expected a performance report")
+	}
+	// the instance over 90% for the most days is first
+	if len(p.HighUtilization) != 2 || p.HighUtilization[0].ID != "i-2" || p.HighUtilization[1].ID != "i-1" {
+		t.Fatalf("high utilization instances are %+v", p.HighUtilization)
+	}
+	if i := p.HighUtilization[1]; i.CPU14DayAverage != 91.5 || i.HighUtilizedDays != 4 || i.Day1 != 90 || i.Day2 != 95 || i.Day3 != 0 {
+		t.Errorf("decoded %+v", i)
+	}
+	if len(p.EBSThroughput) != 1 || p.EBSThroughput[0].TimeNearMaximum != "15%" {
+		t.Errorf("EBS throughput instances are %+v", p.EBSThroughput)
+	}
+	if len(p.SecurityGroupRules) != 1 || p.SecurityGroupRules[0].InboundRules != 120 || p.SecurityGroupRules[0].InstanceCount != 12 {
+		t.Errorf("security groups are %+v", p.SecurityGroupRules)
+	}
+
+	report := p.AsciiReport()
+	// long details wrap, so only their start is on the resource's line
+	var last int
+	for _, s := range []string{"Performance\n",
+		string(chanute.CheckHighUtilizationAmazonEC2Instances), "batch-2", "c5.large averaging 97.0% CPU,", "batch-1",
+		string(chanute.CheckAmazonEC2toEBSThroughputOptimization), "i-3", "m5.large near maximum EBS",
+		string(chanute.CheckLargeNumberofRulesinanEC2SecurityGroup), "120 inbound, 2 outbound rules"} {
+		i := strings.Index(report[last:], s)
+		if i < 0 {
+			t.Fatalf("expected %q after position %d of the report:\n%s", s, last, report)
+		}
+		last += i
+	}
+}
+
+func TestPerformanceReportAggregated(t *testing.T) {
+	r, err := chanute.GenerateClientReport(performanceFakes().Clients(), performanceChecks, chanute.WithAggregationByTag("team"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Performance == nil {
+		t.Fatal("expected a performance report")
+	}
+	counts := map[string]int{}
+	for _, agg := range r.Performance.Aggregated {
+		counts[agg.Key] = agg.Count
+	}
+	if len(counts) != 2 || counts["batch"] != 2 || counts["web"] != 2 {
+		t.Errorf("aggregated counts are %v", counts)
+	}
+}
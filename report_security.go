@@ -0,0 +1,449 @@
+package chanute
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+type SecurityReport struct {
+	UnrestrictedAccess []*UnrestrictedSecurityGroup
+	UnrestrictedPorts  []*UnrestrictedPortSecurityGroup
+	S3Buckets          []*S3BucketPermissions
+	AccessKeyRotation  []*IAMAccessKeyRotation
+	ExposedAccessKeys  []*ExposedAccessKey
+	// RootAccountWithoutMFA is set when the MFA on Root Account check is flagged
+	RootAccountWithoutMFA bool
+
+	Aggregated []*FindingAggregate
+	Suppressed []*Finding
+}
+
+// securityReportChecks is the order checks are reported in
+var securityReportChecks = []Check{
+	CheckExposedAccessKeys,
+	CheckSecurityGroupsUnrestrictedAccess,
+	CheckSecurityGroupsSpecificPortsUnrestricted,
+	CheckAmazonS3BucketPermissions,
+	CheckIAMAccessKeyRotation,
+}
+
+// Findings are every flagged resource, in report order
+func (r *SecurityReport) Findings() []*Finding {
+	var o []*Finding
+	for _, k := range r.ExposedAccessKeys {
+		o = append(o, k.finding())
+	}
+	for _, g := range r.UnrestrictedAccess {
+		o = append(o, g.finding())
+	}
+	for _, g := range r.UnrestrictedPorts {
+		o = append(o, g.finding())
+	}
+	for _, b := range r.S3Buckets {
+		o = append(o, b.finding())
+	}
+	for _, k := range r.AccessKeyRotation {
+		o = append(o, k.finding())
+	}
+	return o
+}
+
+func (r *SecurityReport) AsciiReport() string {
+	return withSuppressedFindings(r.asciiReport(), "Security", r.Suppressed)
+}
+
+func (r *SecurityReport) asciiReport() string {
+	findings := r.Findings()
+	if len(findings) == 0 && !r.RootAccountWithoutMFA {
+		return "Security: No issues"
+	}
+
+	o := &strings.Builder{}
+	o.WriteString("Security\n")
+	if r.RootAccountWithoutMFA {
+		o.WriteString("MFA is not enabled on the root account\n")
+	}
+	if len(findings) > 0 {
+		writeFindings(o, securityReportChecks, findings, r.Aggregated)
+	}
+	return o.String()
+}
+
+// UnrestrictedSecurityGroup is a security group rule that allows access from any address
+type UnrestrictedSecurityGroup struct {
+	FlaggedResource
+
+	Region   string `ta:"Region"`
+	Name     string `ta:"Security Group Name"`
+	ID       string `ta:"Security Group ID"`
+	Protocol string `ta:"Protocol"`
+	Port     string `ta:"Port"`
+	Status   string `ta:"Status"`
+	IPRange  string `ta:"IP Range"`
+
+	Tags map[string]string
+}
+
+func (g *UnrestrictedSecurityGroup) finding() *Finding {
+	return &Finding{
+		Check:  CheckSecurityGroupsUnrestrictedAccess,
+		Name:   g.Name,
+		ID:     g.ID,
+		Region: g.Region,
+		Detail: g.Protocol + " " + g.Port + " from " + g.IPRange,
+		Tags:   g.Tags,
+	}
+}
+
+// UnrestrictedPortSecurityGroup is a security group that leaves high risk ports, like SSH, open to any address
+type UnrestrictedPortSecurityGroup struct {
+	FlaggedResource
+
+	Region   string `ta:"Region"`
+	Name     string `ta:"Security Group Name"`
+	ID       string `ta:"Security Group ID"`
+	Protocol string `ta:"Protocol"`
+	Status   string `ta:"Status"`
+	Ports    string `ta:"Ports"`
+
+	Tags map[string]string
+}
+
+func (g *UnrestrictedPortSecurityGroup) finding() *Finding {
+	return &Finding{
+		Check:  CheckSecurityGroupsSpecificPortsUnrestricted,
+		Name:   g.Name,
+		ID:     g.ID,
+		Region: g.Region,
+		Detail: g.Protocol + " " + g.Ports,
+		Tags:   g.Tags,
+	}
+}
+
+// S3BucketPermissions is a bucket whose ACL or policy allows open access
+type S3BucketPermissions struct {
+	FlaggedResource
+
+	Region                string `ta:"Region API Parameter"`
+	RegionName            string `ta:"Region Name"`
+	Bucket                string `ta:"Bucket Name"`
+	ACLAllowsList         bool   `ta:"ACL Allows List"`
+	ACLAllowsUploadDelete bool   `ta:"ACL Allows Upload/Delete"`
+	PolicyAllowsAccess    bool   `ta:"Policy Allows Access"`
+	Status                string `ta:"Status"`
+
+	Tags map[string]string
+}
+
+func (b *S3BucketPermissions) finding() *Finding {
+	var allows []string
+	if b.ACLAllowsList {
+		allows = append(allows, "ACL allows list")
+	}
+	if b.ACLAllowsUploadDelete {
+		allows = append(allows, "ACL allows upload/delete")
+	}
+	if b.PolicyAllowsAccess {
+		allows = append(allows, "policy allows access")
+	}
+	return &Finding{
+		Check:  CheckAmazonS3BucketPermissions,
+		Name:   b.Bucket,
+		Region: b.Region,
+		Detail: strings.Join(allows, ", "),
+		Tags:   b.Tags,
+	}
+}
+
+// IAMAccessKeyRotation is an active access key that hasn't been rotated recently
+type IAMAccessKeyRotation struct {
+	FlaggedResource
+
+	Status      string `ta:"Status"`
+	User        string `ta:"IAM User"`
+	AccessKey   string `ta:"Access Key"`
+	LastRotated string `ta:"Key Last Rotated"`
+	Reason      string `ta:"Reason"`
+
+	Tags map[string]string
+}
+
+func (k *IAMAccessKeyRotation) finding() *Finding {
+	return &Finding{
+		Check:  CheckIAMAccessKeyRotation,
+		Name:   k.User,
+		ID:     k.AccessKey,
+		Detail: k.Reason,
+		Tags:   k.Tags,
+	}
+}
+
+type ExposedAccessKey struct {
+	FlaggedResource
+
+	AccessKeyID string `ta:"Access Key ID"`
+	User        string `ta:"User Name (IAM or Root)"`
+	FraudType   string `ta:"Fraud Type"`
+	CaseID      string `ta:"Case ID"`
+	Updated     string `ta:"Time Updated"`
+	Location    string `ta:"Location"`
+	Deadline    string `ta:"Deadline"`
+	Usage       string `ta:"Usage (USD per Day)"`
+
+	Tags map[string]string
+}
+
+func (k *ExposedAccessKey) finding() *Finding {
+	return &Finding{
+		Check:  CheckExposedAccessKeys,
+		Name:   k.User,
+		ID:     k.AccessKeyID,
+		Detail: k.FraudType + " at " + k.Location,
+		Tags:   k.Tags,
+	}
+}
+
+func securityReport(ctx context.Context, config *Config, c *Clients, lookups map[Check][]*TrustedAdvisorCheck) (*SecurityReport, error) {
+	r := &SecurityReport{}
+	var err error
+	for lookup, checks := range lookups {
+		var decodeErr error
+		switch lookup {
+		case CheckSecurityGroupsUnrestrictedAccess:
+			var suppressed []*UnrestrictedSecurityGroup
+			decodeErr = decodeReportResources(config, checks, &r.UnrestrictedAccess, &suppressed)
+			for _, g := range suppressed {
+				r.Suppressed = append(r.Suppressed, g.finding())
+			}
+		case CheckSecurityGroupsSpecificPortsUnrestricted:
+			var suppressed []*UnrestrictedPortSecurityGroup
+			decodeErr = decodeReportResources(config, checks, &r.UnrestrictedPorts, &suppressed)
+			for _, g := range suppressed {
+				r.Suppressed = append(r.Suppressed, g.finding())
+			}
+		case CheckAmazonS3BucketPermissions:
+			var suppressed []*S3BucketPermissions
+			decodeErr = decodeReportResources(config, checks, &r.S3Buckets, &suppressed)
+			for _, b := range suppressed {
+				r.Suppressed = append(r.Suppressed, b.finding())
+			}
+		case CheckIAMAccessKeyRotation:
+			var suppressed []*IAMAccessKeyRotation
+			decodeErr = decodeReportResources(config, checks, &r.AccessKeyRotation, &suppressed)
+			for _, k := range suppressed {
+				r.Suppressed = append(r.Suppressed, k.finding())
+			}
+		case CheckExposedAccessKeys:
+			var suppressed []*ExposedAccessKey
+			decodeErr = decodeReportResources(config, checks, &r.ExposedAccessKeys, &suppressed)
+			for _, k := range suppressed {
+				r.Suppressed = append(r.Suppressed, k.finding())
+			}
+		case CheckMFAonRootAccount:
+			// only checks that aren't ok are fetched
+			r.RootAccountWithoutMFA = true
+		}
+		if decodeErr != nil {
+			err = errs.Append(err, decodeErr)
+		}
+	}
+
+	if config.TagProvider != nil {
+		if tagErr := r.getTags(ctx, c, config); tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+	}
+
+	sort.Slice(r.UnrestrictedAccess, func(i, j int) bool {
+		a, b := r.UnrestrictedAccess[i], r.UnrestrictedAccess[j]
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		return a.IPRange < b.IPRange
+	})
+	sort.Slice(r.UnrestrictedPorts, func(i, j int) bool {
+		a, b := r.UnrestrictedPorts[i], r.UnrestrictedPorts[j]
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Ports < b.Ports
+	})
+	sort.Slice(r.S3Buckets, func(i, j int) bool {
+		return r.S3Buckets[i].Bucket < r.S3Buckets[j].Bucket
+	})
+	sort.Slice(r.AccessKeyRotation, func(i, j int) bool {
+		a, b := r.AccessKeyRotation[i], r.AccessKeyRotation[j]
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		return a.AccessKey < b.AccessKey
+	})
+	sort.Slice(r.ExposedAccessKeys, func(i, j int) bool {
+		return r.ExposedAccessKeys[i].AccessKeyID < r.ExposedAccessKeys[j].AccessKeyID
+	})
+	sortFindings(r.Suppressed)
+
+	if config.Aggregator != nil {
+		r.Aggregated = aggregateFindings(config, r.Findings())
+	}
+
+	return r, err
+}
+
+// getTags looks up the tags of security groups and buckets in their regions, and of IAM users
+func (r *SecurityReport) getTags(ctx context.Context, c *Clients, config *Config) error {
+	// bucket names are global, but their tags can only be read in the bucket's region.
+	// IAM users are global, so they have no region.
+	groupIDs, buckets, users := regionalIDs{}, regionalIDs{}, regionalIDs{}
+	for _, g := range r.UnrestrictedAccess {
+		groupIDs.add(g.Region, g.ID)
+	}
+	for _, g := range r.UnrestrictedPorts {
+		groupIDs.add(g.Region, g.ID)
+	}
+	for _, b := range r.S3Buckets {
+		buckets.add(b.Region, b.Bucket)
+	}
+	for _, k := range r.AccessKeyRotation {
+		users.add("", k.User)
+	}
+	for _, k := range r.ExposedAccessKeys {
+		users.add("", k.User)
+	}
+
+	var err error
+	if len(groupIDs) > 0 {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindSecurityGroup, groupIDs)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, g := range r.UnrestrictedAccess {
+			g.Tags = tags.Get(g.Region, g.ID)
+		}
+		for _, g := range r.UnrestrictedPorts {
+			g.Tags = tags.Get(g.Region, g.ID)
+		}
+	}
+	if len(buckets) > 0 {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindS3Bucket, buckets)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, b := range r.S3Buckets {
+			b.Tags = tags.Get(b.Region, b.Bucket)
+		}
+	}
+	if len(users) > 0 {
+		tags, tagErr := tagsByRegion(ctx, c, config, TagKindIAMUser, users)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for _, k := range r.AccessKeyRotation {
+			k.Tags = tags.Get("", k.User)
+		}
+		for _, k := range r.ExposedAccessKeys {
+			k.Tags = tags.Get("", k.User)
+		}
+	}
+	return err
+}
+
+// GetSecurityGroupTags is GetSecurityGroupTagsWithContext using a background context
+func GetSecurityGroupTags(c *Clients, ids []*string) (TagMap, error) {
+	return GetSecurityGroupTagsWithContext(context.Background(), c, ids)
+}
+
+// GetSecurityGroupTagsWithContext filters by group-id, so groups that no longer exist are left out instead of failing the call
+func GetSecurityGroupTagsWithContext(ctx context.Context, c *Clients, ids []*string) (TagMap, error) {
+	input := &ec2.DescribeSecurityGroupsInput{
+		Filters: []*ec2.Filter{{Name: aws.String("group-id"), Values: ids}},
+	}
+
+	tags := map[string]map[string]string{}
+	for {
+		page, err := c.EC2.DescribeSecurityGroupsWithContext(ctx, input)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		for _, sg := range page.SecurityGroups {
+			tags[aws.StringValue(sg.GroupId)] = ec2TagsToMap(sg.Tags)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+	return tags, nil
+}
+
+// GetS3BucketTags is GetS3BucketTagsWithContext using a background context
+func GetS3BucketTags(c *Clients, buckets []*string) (TagMap, error) {
+	return GetS3BucketTagsWithContext(context.Background(), c, buckets)
+}
+
+// GetS3BucketTagsWithContext leaves out buckets that no longer exist, and buckets without tags
+func GetS3BucketTagsWithContext(ctx context.Context, c *Clients, buckets []*string) (TagMap, error) {
+	tags := map[string]map[string]string{}
+	for name := range stringPtrSet(buckets) {
+		o, err := c.S3.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{Bucket: aws.String(name)})
+		if err != nil {
+			if ae, ok := err.(awserr.Error); ok && (ae.Code() == "NoSuchTagSet" || ae.Code() == s3.ErrCodeNoSuchBucket) {
+				continue
+			}
+			return nil, errs.Wrap(err)
+		}
+		tags[name] = map[string]string{}
+		for _, t := range o.TagSet {
+			tags[name][aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+		}
+	}
+	return tags, nil
+}
+
+// GetIAMUserTags is GetIAMUserTagsWithContext using a background context
+func GetIAMUserTags(c *Clients, users []*string) (TagMap, error) {
+	return GetIAMUserTagsWithContext(context.Background(), c, users)
+}
+
+// GetIAMUserTagsWithContext leaves out users that no longer exist, such as the root account
+func GetIAMUserTagsWithContext(ctx context.Context, c *Clients, users []*string) (TagMap, error) {
+	tags := map[string]map[string]string{}
+	for name := range stringPtrSet(users) {
+		input := &iam.ListUserTagsInput{UserName: aws.String(name)}
+		userTags := map[string]string{}
+		for {
+			page, err := c.IAM.ListUserTagsWithContext(ctx, input)
+			if err != nil {
+				if ae, ok := err.(awserr.Error); ok && ae.Code() == iam.ErrCodeNoSuchEntityException {
+					userTags = nil
+					break
+				}
+				return nil, errs.Wrap(err)
+			}
+			for _, t := range page.Tags {
+				userTags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+			}
+
+			if !aws.BoolValue(page.IsTruncated) {
+				break
+			}
+			input.Marker = page.Marker
+		}
+		if userTags != nil {
+			tags[name] = userTags
+		}
+	}
+	return tags, nil
+}
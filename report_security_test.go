@@ -0,0 +1,45 @@
+package chanute_test
+
+import (
+	"testing"
+
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+var s3BucketPermissionsColumns = []string{
+	"Region Name",
+	"Region API Parameter",
+	"Bucket Name",
+	"ACL Allows List",
+	"ACL Allows Upload/Delete",
+	"Status",
+	"Policy Allows Access",
+}
+
+func TestSecurityReportBucketTagsInBucketRegion(t *testing.T) {
+	f := chanutetest.New()
+	f.Support.AddCheck("Pfx0RwqBli", string(chanute.CheckAmazonS3BucketPermissions), "security", s3BucketPermissionsColumns,
+		[]string{"US East (N. Virginia)", "us-east-1", "local-bucket", "Yes", "No", "Yellow", "No"},
+		[]string{"US West (Oregon)", "us-west-2", "remote-bucket", "No", "No", "Red", "Yes"},
+	)
+	f.S3.AddBucket("local-bucket", map[string]string{"team": "data"})
+	// the session region doesn't have the bucket, so looking it up there fails
+	f.InRegion("us-west-2").S3.AddBucket("remote-bucket", map[string]string{"team": "web"})
+
+	r, err := chanute.GenerateClientReport(f.Clients(),
+		chanute.WithChecks(chanute.CheckAmazonS3BucketPermissions),
+		chanute.WithAggregationByTag("team"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Security == nil || len(r.Security.S3Buckets) != 2 {
+		t.Fatalf("expected 2 buckets, got %+v", r.Security)
+	}
+	for _, b := range r.Security.S3Buckets {
+		want := map[string]string{"local-bucket": "data", "remote-bucket": "web"}[b.Bucket]
+		if got := b.Tags["team"]; got != want {
+			t.Errorf("%s: team tag is %q, expected %q", b.Bucket, got, want)
+		}
+	}
+}
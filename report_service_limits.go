@@ -1,15 +1,18 @@
 package chanute
 
 import (
+	"context"
+	"sort"
 	"strconv"
 	"strings"
 
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/olekukonko/tablewriter"
+	"github.com/richardwilkes/toolbox/errs"
 )
 
 type LimitReport struct {
-	Limits []*ServiceLimit
+	Limits     []*ServiceLimit
+	Suppressed []*ServiceLimit
 }
 
 func (r *LimitReport) Title() string {
@@ -35,20 +38,21 @@ func (r *LimitReport) Rows(env ...string) [][]string {
 		if len(env) == 1 && env[0] != "" {
 			row = append(row, env[0])
 		}
-		row = append(row,
-			l.Status,
-			l.Service,
-			l.LimitName,
-			l.Region,
-			strconv.Itoa(l.LimitAmount),
-			strconv.Itoa(l.CurrentUsage),
-		)
+		row = append(row, l.row()...)
 		o = append(o, row)
 	}
 	return o
 }
 
 func (r *LimitReport) AsciiReport() string {
+	var suppressed [][]string
+	for _, l := range r.Suppressed {
+		suppressed = append(suppressed, l.row())
+	}
+	return withSuppressed(r.asciiReport(), "Service Limits", r.Headers(), suppressed)
+}
+
+func (r *LimitReport) asciiReport() string {
 	if len(r.Limits) == 0 {
 		return "Service Limits: No issues"
 	}
@@ -57,46 +61,63 @@ func (r *LimitReport) AsciiReport() string {
 
 	w := tablewriter.NewWriter(o)
 
-	w.SetHeader([]string{"Status", "Service", "Limit Name", "Region", "Limit Amount", "Current Usage"})
+	w.SetHeader(r.Headers())
 	for _, l := range r.Limits {
-		w.Append([]string{
-			l.Status,
-			l.Service,
-			l.LimitName,
-			l.Region,
-			strconv.Itoa(l.LimitAmount),
-			strconv.Itoa(l.CurrentUsage),
-		})
+		w.Append(l.row())
 	}
 	w.Render()
 	return o.String()
 }
 
+// ServiceLimit is a limit whose usage is close to or over it
 type ServiceLimit struct {
-	Service, Region, Status, LimitName string
-	LimitAmount, CurrentUsage          int
+	FlaggedResource
+
+	Service      string `ta:"Service"`
+	Region       string `ta:"Region"`
+	Status       string `ta:"Status"`
+	LimitName    string `ta:"Limit Name"`
+	LimitAmount  int    `ta:"Limit Amount"`
+	CurrentUsage int    `ta:"Current Usage"`
+}
+
+func (l *ServiceLimit) row() []string {
+	return []string{
+		l.Status,
+		l.Service,
+		l.LimitName,
+		l.Region,
+		strconv.Itoa(l.LimitAmount),
+		strconv.Itoa(l.CurrentUsage),
+	}
 }
 
-func serviceLimits(config *Config, sess *session.Session, lookups map[Check][]*TrustedAdvisorCheck) (*LimitReport, error) {
+func serviceLimits(ctx context.Context, config *Config, c *Clients, lookups map[Check][]*TrustedAdvisorCheck) (*LimitReport, error) {
 	r := &LimitReport{}
+	var err error
 	for _, checks := range lookups {
-		m := checksToMaps(checks)
+		var decoded []*ServiceLimit
+		if decodeErr := decodeReportResources(config, checks, &decoded, &r.Suppressed); decodeErr != nil {
+			err = errs.Append(err, decodeErr)
+		}
 
-		for _, lim := range m {
-			sts := lim["Status"]
-			if sts == "Green" {
+		for _, lim := range decoded {
+			if lim.Status == "Green" {
 				continue
 			}
-
-			r.Limits = append(r.Limits, &ServiceLimit{
-				Service:      lim["Service"],
-				LimitName:    lim["Limit Name"],
-				LimitAmount:  parseAmount(lim["Limit Amount"]),
-				CurrentUsage: parseAmount(lim["Current Usage"]),
-				Status:       sts,
-				Region:       lim["Region"],
-			})
+			r.Limits = append(r.Limits, lim)
 		}
 	}
-	return r, nil
+
+	sort.Slice(r.Limits, func(i, j int) bool {
+		a, b := r.Limits[i], r.Limits[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.LimitName != b.LimitName {
+			return a.LimitName < b.LimitName
+		}
+		return a.Region < b.Region
+	})
+	return r, err
 }
@@ -0,0 +1,291 @@
+package chanute_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+// slowSupport takes delays[id] to fetch a check's result, fails the checks in fail,
+// and records how many results are fetched at once
+type slowSupport struct {
+	*chanutetest.Support
+	delays map[string]time.Duration
+	fail   map[string]bool
+	// cancel is called by the first fetch, which then waits for the context to be done
+	cancel context.CancelFunc
+
+	mu                       sync.Mutex
+	calls, active, maxActive int
+}
+
+func (s *slowSupport) DescribeTrustedAdvisorCheckResultWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckResultInput, opts ...request.Option) (*support.DescribeTrustedAdvisorCheckResultOutput, error) {
+	s.mu.Lock()
+	s.calls++
+	first := s.calls == 1
+	s.active++
+	if s.active > s.maxActive {
+		s.maxActive = s.active
+	}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+	}()
+
+	if first && s.cancel != nil {
+		s.cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	id := aws.StringValue(in.CheckId)
+	time.Sleep(s.delays[id])
+	if s.fail[id] {
+		return nil, awserr.New("Throttling", "Rate exceeded", nil)
+	}
+	return s.Support.DescribeTrustedAdvisorCheckResultWithContext(ctx, in, opts...)
+}
+
+func addFlaggedChecks(f *chanutetest.Fakes, n int) []string {
+	var ids []string
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("check-%02d", i)
+		f.Support.AddCheck(id, "Check "+id, "security", []string{"Region"}, []string{"us-east-1"})
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestListNonOKTrustedAdvisorChecksKeepsOrder(t *testing.T) {
+	f := chanutetest.New()
+	ids := addFlaggedChecks(f, 12)
+	f.Support.AddCheck("check-ok", "Check ok", "security", nil)
+	s := &slowSupport{
+		Support: f.Support,
+		delays:  map[string]time.Duration{},
+		fail:    map[string]bool{"check-03": true, "check-07": true},
+	}
+	// the first checks take the longest, so they finish last
+	for i, id := range ids {
+		s.delays[id] = time.Duration(len(ids)-i) * 2 * time.Millisecond
+	}
+
+	checks, err := chanute.ListNonOKTrustedAdvisorChecks(s, nil)
+	if err == nil {
+		t.Fatal("expected the failed checks' errors")
+	}
+	for _, id := range []string{"check-03", "check-07"} {
+		if !strings.Contains(err.Error(), "("+id+"): Throttling") {
+			t.Errorf("expected an error for %s, got %v", id, err)
+		}
+	}
+
+	var got []string
+	for _, ch := range checks {
+		got = append(got, ch.ID)
+	}
+	var want []string
+	for _, id := range ids {
+		if !s.fail[id] {
+			want = append(want, id)
+		}
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got checks %v, expected %v", got, want)
+	}
+	if s.maxActive < 2 || s.maxActive > 4 {
+		t.Errorf("expected between 2 and 4 results to be fetched at once, got %d", s.maxActive)
+	}
+}
+
+func TestListNonOKTrustedAdvisorChecksStopsWhenCancelled(t *testing.T) {
+	f := chanutetest.New()
+	ids := addFlaggedChecks(f, 20)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &slowSupport{Support: f.Support, cancel: cancel}
+
+	checks, err := chanute.ListNonOKTrustedAdvisorChecksWithContext(ctx, s, nil)
+	if err == nil {
+		t.Fatal("expected the cancellation to fail the checks")
+	}
+	// the checks already sent to a worker are fetched, nothing is sent after the cancellation
+	if s.calls > 5 {
+		t.Errorf("expected at most 5 fetches, got %d", s.calls)
+	}
+	if failed := strings.Count(err.Error(), context.Canceled.Error()); len(checks)+failed != len(ids) || failed < len(ids)-5 {
+		t.Errorf("expected the unfetched checks to be cancelled, got %d checks and %v", len(checks), err)
+	}
+}
+
+// dayColumns are the daily utilization columns of the EC2 utilization checks, rows can leave them out
+var dayColumns = []string{"Day 1", "Day 2", "Day 3", "Day 4", "Day 5", "Day 6", "Day 7", "Day 8", "Day 9", "Day 10", "Day 11", "Day 12", "Day 13", "Day 14"}
+
+// allSectionsFakes has a flagged resource for every report section, tagged with the service that holds its tags
+func allSectionsFakes() *chanutetest.Fakes {
+	f := chanutetest.New()
+	f.Support.AddCheck("Qch7DwouX1", string(chanute.CheckLowUtilizationAmazonEC2Instances), "cost_optimizing",
+		append([]string{"Region/AZ", "Instance ID", "Instance Name", "Instance Type", "Estimated Monthly Savings", "Number of Days Low Utilization", "14-Day Average CPU Utilization", "14-Day Average Network I/O"}, dayColumns...),
+		[]string{"us-east-1a", "i-1", "web-1", "m5.large", "$10", "14 days"},
+	)
+	f.Support.AddCheck("hjLMh88uM8", string(chanute.CheckIdleLoadBalancers), "cost_optimizing",
+		[]string{"Region", "Load Balancer Name", "Reason", "Estimated Monthly Savings"},
+		[]string{"us-east-1", "app-lb", "Low request count", "$18"},
+	)
+	f.Support.AddCheck("G31sQ1E9U", string(chanute.CheckUnderutilizedAmazonRedshiftClusters), "cost_optimizing",
+		[]string{"Status", "Region", "Cluster", "Instance Type", "Reason", "Estimated Monthly Savings"},
+		[]string{"Yellow", "us-east-1", "warehouse", "dc2.large", "Low CPU", "$180"},
+	)
+	f.Support.AddCheck("Pfx0RwqBli", string(chanute.CheckAmazonS3BucketPermissions), "security", s3BucketPermissionsColumns,
+		[]string{"US East (N. Virginia)", "us-east-1", "logs", "Yes", "No", "Yellow", "No"},
+	)
+	f.Support.AddCheck("DqdJqYeRm5", string(chanute.CheckIAMAccessKeyRotation), "security",
+		[]string{"Status", "IAM User", "Access Key", "Key Last Rotated", "Reason"},
+		[]string{"Red", "deploy", "AKIA1", "2019-01-01", "Key not rotated in 365 days"},
+	)
+	f.Support.AddCheck("xdeXZKIUy", string(chanute.CheckELBCrossZoneLoadBalancing), "fault_tolerance",
+		[]string{"Region", "Load Balancer Name", "Reason"},
+		[]string{"us-east-1", "classic-lb", "Cross-zone load balancing disabled"},
+	)
+	f.Support.AddCheck("ZRxQlPsb6c", string(chanute.CheckHighUtilizationAmazonEC2Instances), "performance",
+		append([]string{"Region/AZ", "Instance ID", "Instance Name", "Instance Type", "14-Day Average CPU Utilization", "Number of Days over 90% CPU Utilization"}, dayColumns...),
+		[]string{"us-east-1b", "i-2", "batch-1", "c5.large", "95%", "4 days"},
+	)
+	f.Support.AddCheck("0Xc6LMYG8P", string(chanute.CheckEC2OnDemandInstances), "service_limits",
+		[]string{"Region", "Service", "Limit Name", "Limit Amount", "Current Usage", "Status"},
+		[]string{"us-east-1", "EC2", "On-Demand instances - m5.large", "20", "18", "Yellow"},
+	)
+
+	f.EC2.AddInstance("i-1", map[string]string{"team": "ec2"})
+	f.EC2.AddInstance("i-2", map[string]string{"team": "ec2"})
+	f.ELBv2.AddLoadBalancer("app-lb", "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/app-lb/1", map[string]string{"team": "elbv2"})
+	f.Redshift.AddCluster("warehouse", map[string]string{"team": "redshift"})
+	f.S3.AddBucket("logs", map[string]string{"team": "s3"})
+	f.IAM.AddUser("deploy", map[string]string{"team": "iam"})
+	f.ELB.AddLoadBalancer("classic-lb", map[string]string{"team": "elb"})
+
+	f.ResourceGroupsTagging.AddResource("arn:aws:ec2:us-east-1:123456789012:instance/i-1", map[string]string{"team": "tagging ec2"})
+	f.ResourceGroupsTagging.AddResource("arn:aws:ec2:us-east-1:123456789012:instance/i-2", map[string]string{"team": "tagging ec2"})
+	f.ResourceGroupsTagging.AddResource("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/app-lb/1", map[string]string{"team": "tagging elbv2"})
+	f.ResourceGroupsTagging.AddResource("arn:aws:redshift:us-east-1:123456789012:cluster:warehouse", map[string]string{"team": "tagging redshift"})
+	f.ResourceGroupsTagging.AddResource("arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/classic-lb", map[string]string{"team": "tagging elb"})
+	return f
+}
+
+func TestGenerateClientReportAllSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []chanute.Option
+		// tagging is the prefix of the tags looked up in the Resource Groups Tagging API
+		tagging string
+	}{
+		{name: "service APIs"},
+		{name: "tagging API", options: []chanute.Option{chanute.WithResourceGroupsTaggingAPI()}, tagging: "tagging "},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := append([]chanute.Option{
+				chanute.WithCostOptimizationChecks(),
+				chanute.WithSecurityChecks(),
+				chanute.WithFaultToleranceChecks(),
+				chanute.WithPerformanceChecks(),
+				chanute.WithServiceLimitChecks(),
+				chanute.WithAggregationByTag("team"),
+			}, test.options...)
+			r, err := chanute.GenerateClientReport(allSectionsFakes().Clients(), options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Account != "123456789012" {
+				t.Errorf("expected the STS account, got %q", r.Account)
+			}
+			if r.CostOptimization == nil || r.Security == nil || r.FaultTolerance == nil || r.Performance == nil || r.ServiceLimits == nil {
+				t.Fatalf("expected every section, got %+v", r)
+			}
+
+			teams := map[string]map[string]string{}
+			if ec2 := r.CostOptimization.EC2; ec2 != nil && len(ec2.Instances) == 1 {
+				teams["low utilization ec2"] = ec2.Instances[0].Tags
+			}
+			if lbs := r.CostOptimization.LoadBalancers; lbs != nil && len(lbs.LoadBalancers) == 1 {
+				teams["idle load balancer"] = lbs.LoadBalancers[0].Tags
+			}
+			if rs := r.CostOptimization.Redshift; rs != nil && len(rs.Clusters) == 1 {
+				teams["redshift"] = rs.Clusters[0].Tags
+			}
+			if len(r.Security.S3Buckets) == 1 {
+				teams["bucket"] = r.Security.S3Buckets[0].Tags
+			}
+			if len(r.Security.AccessKeyRotation) == 1 {
+				teams["access key"] = r.Security.AccessKeyRotation[0].Tags
+			}
+			if len(r.FaultTolerance.CrossZoneELBs) == 1 {
+				teams["cross zone elb"] = r.FaultTolerance.CrossZoneELBs[0].Tags
+			}
+			if len(r.Performance.HighUtilization) == 1 {
+				teams["high utilization ec2"] = r.Performance.HighUtilization[0].Tags
+			}
+			want := map[string]string{
+				"low utilization ec2":  test.tagging + "ec2",
+				"idle load balancer":   test.tagging + "elbv2",
+				"redshift":             test.tagging + "redshift",
+				"bucket":               "s3",
+				"access key":           "iam",
+				"cross zone elb":       test.tagging + "elb",
+				"high utilization ec2": test.tagging + "ec2",
+			}
+			for resource, team := range want {
+				tags, ok := teams[resource]
+				if !ok {
+					t.Errorf("%s wasn't reported", resource)
+				} else if tags["team"] != team {
+					t.Errorf("%s: team tag is %q, expected %q", resource, tags["team"], team)
+				}
+			}
+			if len(r.ServiceLimits.Limits) != 1 || r.ServiceLimits.Limits[0].CurrentUsage != 18 {
+				t.Errorf("expected the on-demand instance limit, got %+v", r.ServiceLimits.Limits)
+			}
+			if r.AsciiReport() == "" {
+				t.Error("expected a report")
+			}
+		})
+	}
+}
+
+func TestGenerateClientReportInAnotherLanguage(t *testing.T) {
+	f := chanutetest.New()
+	f.Support.AddCheck("Ti39halfu8", string(chanute.CheckAmazonRDSIdleDBInstances), "cost_optimizing", rdsIdleColumns,
+		[]string{"us-east-1", "orders", "No", "db.m5.large", "100", "14+", "$120"},
+	)
+	// the columns are only matched to fields by their position in the English description
+	f.Support.AddTranslation("ja", "Ti39halfu8", "Amazon RDS アイドル状態の DB インスタンス",
+		[]string{"リージョン", "DB インスタンス名", "マルチ AZ", "インスタンスタイプ", "プロビジョニングされたストレージ (GB)", "前回の接続からの日数", "月間の推定削減額 (オンデマンド)"})
+
+	r, err := chanute.GenerateClientReport(f.Clients(),
+		chanute.WithChecks(chanute.CheckAmazonRDSIdleDBInstances),
+		chanute.WithLanguage("ja"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.MissingChecks) != 0 {
+		t.Errorf("expected the translated check to be found, missing %v", r.MissingChecks)
+	}
+	if r.CostOptimization == nil || r.CostOptimization.RDS == nil || len(r.CostOptimization.RDS.Instances) != 1 {
+		t.Fatalf("expected an RDS instance, got %+v", r.CostOptimization)
+	}
+	i := r.CostOptimization.RDS.Instances[0]
+	if i.Name != "orders" || i.Type != "db.m5.large" || i.StorageProvisionedGB != 100 || i.DaysSinceLastConnection != 15 || i.EstimatedMonthlySavings != 120 {
+		t.Errorf("decoded %+v", i)
+	}
+}
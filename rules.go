@@ -0,0 +1,245 @@
+package chanute
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/richardwilkes/toolbox/errs"
+	"gopkg.in/yaml.v2"
+)
+
+// AggregationRules are ownership rules, usually read from a file by AggregatorFromFile:
+//
+//	rules:
+//	  - tag: team
+//	  - tag: organization
+//	    separator: ":::"
+//	    key: team
+//	  - tag: Name
+//	    regex: "^([a-z]+)-"
+//	  - accounts: ["123456789012"]
+//	    value: platform
+//	  - name_prefix: ci-
+//	    value: build
+//	  - value: unowned
+//	aliases:
+//	  sre: site reliability
+//
+// Rules are tried in order and the first one with a key wins, then the key is renamed by Aliases.
+type AggregationRules struct {
+	Rules   []*AggregationRule `json:"rules" yaml:"rules"`
+	Aliases map[string]string  `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+
+	// Log gets a line for each resource that is aggregated, with the rule that matched it.
+	// Reports aggregate a resource more than once, but it is only logged the first time.
+	Log io.Writer `json:"-" yaml:"-"`
+
+	mu     sync.Mutex
+	logged map[string]bool
+}
+
+// AggregationRule reads its key from Tag, or is Value.
+// A tag is read like NormalizedTag, with Regex like TagRegex, or with Separator like ParsedKV.
+// Accounts and NamePrefix limit the rule to resources in those accounts, or whose NameTag starts with NamePrefix.
+type AggregationRule struct {
+	// Name identifies the rule in Log, it's "rule <n>" if empty
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	Tag         string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Regex       string `json:"regex,omitempty" yaml:"regex,omitempty"`
+	Separator   string `json:"separator,omitempty" yaml:"separator,omitempty"`
+	KVSeparator string `json:"kv_separator,omitempty" yaml:"kv_separator,omitempty"`
+	Key         string `json:"key,omitempty" yaml:"key,omitempty"`
+	Value       string `json:"value,omitempty" yaml:"value,omitempty"`
+
+	Accounts   []string `json:"accounts,omitempty" yaml:"accounts,omitempty"`
+	NamePrefix string   `json:"name_prefix,omitempty" yaml:"name_prefix,omitempty"`
+
+	aggregator Aggregator
+}
+
+// AggregatorFromFile reads rules from a .json, .yaml or .yml file, log is their Log and can be nil
+func AggregatorFromFile(path string, log io.Writer) (Aggregator, error) {
+	r, err := AggregationRulesFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r.Log = log
+	return r.Aggregate, nil
+}
+
+// AggregationRulesFromFile reads and validates rules, unknown fields are an error so typos aren't ignored
+func AggregationRulesFromFile(path string) (*AggregationRules, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	r := &AggregationRules{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.DisallowUnknownFields()
+		err = d.Decode(r)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(b, r)
+	default:
+		return nil, errs.New("unsupported rules file type " + ext + ", expected .json, .yaml or .yml")
+	}
+	if err != nil {
+		return nil, errs.NewWithCause("reading "+path, err)
+	}
+
+	if err = r.Compile(); err != nil {
+		return nil, errs.NewWithCause("invalid rules in "+path, err)
+	}
+	return r, nil
+}
+
+// Compile validates the rules and prepares them for Aggregate, it's needed for rules that weren't read from a file
+func (r *AggregationRules) Compile() error {
+	if len(r.Rules) == 0 {
+		return errs.New("no rules")
+	}
+
+	var err error
+	for i, rule := range r.Rules {
+		if rule.Name == "" {
+			rule.Name = "rule " + strconv.Itoa(i+1)
+		}
+		if ruleErr := rule.compile(); ruleErr != nil {
+			msg := ruleErr.Error()
+			// skip the stack trace
+			if e, ok := ruleErr.(*errs.Error); ok {
+				msg = e.Message()
+			}
+			err = errs.Append(err, errs.New(rule.Name+": "+msg))
+			continue
+		}
+		if rule.catchAll() && i < len(r.Rules)-1 {
+			err = errs.Append(err, errs.New(rule.Name+" always matches, so the rules after it never will"))
+		}
+	}
+
+	aliases := make(map[string]string, len(r.Aliases))
+	for from, to := range r.Aliases {
+		aliases[normalizeKey(from)] = to
+	}
+	r.Aliases = aliases
+	return err
+}
+
+func (rule *AggregationRule) compile() error {
+	switch {
+	case rule.Tag == "" && rule.Value == "":
+		return errs.New("needs a tag or a value")
+	case rule.Tag != "" && rule.Value != "":
+		return errs.New("can't have both a tag and a value")
+	case rule.Tag == "" && (rule.Regex != "" || rule.Separator != "" || rule.Key != ""):
+		return errs.New("regex, separator and key need a tag")
+	case rule.Regex != "" && rule.Separator != "":
+		return errs.New("can't have both a regex and a separator")
+	case rule.Separator != "" && rule.Key == "":
+		return errs.New("separator needs a key")
+	case rule.Separator == "" && (rule.Key != "" || rule.KVSeparator != ""):
+		return errs.New("key and kv_separator need a separator")
+	}
+
+	switch {
+	case rule.Value != "":
+		rule.aggregator = Default(rule.Value)
+	case rule.Regex != "":
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return errs.New(err.Error())
+		}
+		rule.aggregator = TagRegex(rule.Tag, re)
+	case rule.Separator != "":
+		kvSep := rule.KVSeparator
+		if kvSep == "" {
+			kvSep = "="
+		}
+		rule.aggregator = ParsedKV(rule.Tag, rule.Separator, kvSep, rule.Key)
+	default:
+		rule.aggregator = NormalizedTag(rule.Tag)
+	}
+	return nil
+}
+
+// catchAll is a value without conditions
+func (rule *AggregationRule) catchAll() bool {
+	return rule.Value != "" && len(rule.Accounts) == 0 && rule.NamePrefix == ""
+}
+
+func (rule *AggregationRule) applies(tags map[string]string) bool {
+	if rule.NamePrefix != "" && !strings.HasPrefix(tags[NameTag], rule.NamePrefix) {
+		return false
+	}
+	if len(rule.Accounts) > 0 {
+		for _, a := range rule.Accounts {
+			if a == tags[AccountTag] {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Match is the key of a resource and the rule that found it, the rule is nil if none did
+func (r *AggregationRules) Match(tags map[string]string) (string, *AggregationRule) {
+	for _, rule := range r.Rules {
+		if rule.aggregator == nil || !rule.applies(tags) {
+			continue
+		}
+		key := rule.aggregator(tags)
+		if key == "" {
+			continue
+		}
+		if alias, ok := r.Aliases[normalizeKey(key)]; ok {
+			key = alias
+		}
+		return key, rule
+	}
+	return "", nil
+}
+
+// Aggregate is Match as an Aggregator, it writes the match to Log as
+//
+//	[<account> ]<name> matched <rule> as "<key>"
+func (r *AggregationRules) Aggregate(tags map[string]string) string {
+	key, rule := r.Match(tags)
+	if r.Log != nil {
+		name := tags[NameTag]
+		if name == "" {
+			name = "(unnamed)"
+		}
+		if account := tags[AccountTag]; account != "" {
+			name = account + " " + name
+		}
+		line := name + " matched no rule\n"
+		if rule != nil {
+			line = fmt.Sprintf("%s matched %s as %q\n", name, rule.Name, key)
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.logged[line] {
+			return key
+		}
+		if r.logged == nil {
+			r.logged = map[string]bool{}
+		}
+		r.logged[line] = true
+		_, _ = io.WriteString(r.Log, line)
+	}
+	return key
+}
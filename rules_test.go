@@ -0,0 +1,146 @@
+package chanute
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAggregationRulesCompile(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []*AggregationRule
+		err   string
+	}{
+		{name: "valid", rules: []*AggregationRule{
+			{Tag: "team"},
+			{Tag: "organization", Separator: ":::", Key: "team"},
+			{Tag: "Name", Regex: "^([a-z]+)-"},
+			{Accounts: []string{"123456789012"}, Value: "platform"},
+			{Value: "unowned"},
+		}},
+		{name: "no rules", err: "no rules"},
+		{name: "bad regex", rules: []*AggregationRule{{Tag: "Name", Regex: "("}}, err: "rule 1: error parsing regexp"},
+		{name: "tag and value", rules: []*AggregationRule{{Tag: "team", Value: "data"}}, err: "rule 1: can't have both a tag and a value"},
+		{name: "neither", rules: []*AggregationRule{{Name: "empty"}}, err: "empty: needs a tag or a value"},
+		{name: "regex without a tag", rules: []*AggregationRule{{Regex: "x", Value: "data"}}, err: "regex, separator and key need a tag"},
+		{name: "regex and separator", rules: []*AggregationRule{{Tag: "t", Regex: "x", Separator: ";", Key: "k"}}, err: "can't have both a regex and a separator"},
+		{name: "separator without a key", rules: []*AggregationRule{{Tag: "t", Separator: ";"}}, err: "separator needs a key"},
+		{name: "key without a separator", rules: []*AggregationRule{{Tag: "t", Key: "k"}}, err: "key and kv_separator need a separator"},
+		{name: "catch all before the end", rules: []*AggregationRule{{Value: "unowned"}, {Tag: "team"}}, err: "rule 1 always matches"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := (&AggregationRules{Rules: test.rules}).Compile()
+			switch {
+			case test.err == "" && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+				t.Errorf("expected %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestAggregationRulesFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chanute")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		file     string
+		contents string
+		err      string
+	}{
+		{file: "rules.yaml", contents: "rules:\n  - tag: team\n  - value: unowned\naliases:\n  SRE: site reliability\n"},
+		{file: "rules.yml", contents: "rules:\n  - tag: team\n"},
+		{file: "rules.json", contents: `{"rules": [{"tag": "organization", "separator": ":::", "kv_separator": "=", "key": "team"}]}`},
+		{file: "unknown.yaml", contents: "rules:\n  - tag: team\n    separater: \":::\"\n", err: "separater"},
+		{file: "unknown.json", contents: `{"rules": [{"tag": "team"}], "aliasez": {}}`, err: "aliasez"},
+		{file: "regex.yaml", contents: "rules:\n  - tag: Name\n    regex: \"(\"\n", err: "rule 1: error parsing regexp"},
+		{file: "rules.toml", contents: "", err: "unsupported rules file type .toml"},
+	}
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			path := filepath.Join(dir, test.file)
+			if err := ioutil.WriteFile(path, []byte(test.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := AggregationRulesFromFile(path)
+			switch {
+			case test.err == "" && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+				t.Errorf("expected %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestAggregationRulesMatch(t *testing.T) {
+	r := &AggregationRules{
+		Rules: []*AggregationRule{
+			{Name: "ci", NamePrefix: "ci-", Value: "build"},
+			{Tag: "team"},
+			{Tag: "organization", Separator: ":::", Key: "team"},
+			{Name: "platform account", Accounts: []string{"123456789012"}, Value: "platform"},
+			{Name: "default", Value: "unowned"},
+		},
+		Aliases: map[string]string{"SRE": "site reliability"},
+	}
+	if err := r.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		tags map[string]string
+		key  string
+		rule string
+	}{
+		{"tag", map[string]string{"Team": "Data"}, "data", "rule 2"},
+		{"alias", map[string]string{"team": "sre"}, "site reliability", "rule 2"},
+		{"parsed", map[string]string{"organization": "team=web"}, "web", "rule 3"},
+		{"name prefix", aggregationTags(map[string]string{"team": "data"}, "ci-runner", "i-1"), "build", "ci"},
+		{"account", map[string]string{AccountTag: "123456789012"}, "platform", "platform account"},
+		{"other account", map[string]string{AccountTag: "210987654321"}, "unowned", "default"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, rule := r.Match(test.tags)
+			if key != test.key || rule == nil || rule.Name != test.rule {
+				t.Errorf("got %q from %+v, expected %q from %s", key, rule, test.key, test.rule)
+			}
+		})
+	}
+}
+
+func TestAggregationRulesLogOncePerResource(t *testing.T) {
+	log := &strings.Builder{}
+	r := &AggregationRules{
+		Rules: []*AggregationRule{{Tag: "team"}, {Value: "unowned"}},
+		Log:   log,
+	}
+	if err := r.Compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	// reports aggregate each resource more than once
+	for i := 0; i < 3; i++ {
+		withAccount(r.Aggregate, "123456789012")(aggregationTags(map[string]string{"Team": "Data"}, "web-1", "i-1"))
+		withAccount(r.Aggregate, "123456789012")(aggregationTags(nil, "", "i-2"))
+		withAccount(r.Aggregate, "210987654321")(aggregationTags(nil, "", "i-2"))
+	}
+
+	want := `123456789012 web-1 matched rule 1 as "data"
+123456789012 i-2 matched rule 2 as "unowned"
+210987654321 i-2 matched rule 2 as "unowned"
+`
+	if log.String() != want {
+		t.Errorf("got:\n%s\nexpected:\n%s", log.String(), want)
+	}
+}
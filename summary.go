@@ -0,0 +1,150 @@
+package chanute
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/aws/aws-sdk-go/service/support/supportiface"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// summaryBatchSize is how many checks are summarized per DescribeTrustedAdvisorCheckSummaries call
+const summaryBatchSize = 50
+
+// WithSummaryOnly only fetches check summaries, which is much faster than fetching every flagged resource.
+// The report will only have a Summary.
+func WithSummaryOnly() Option {
+	return func(c *Config) {
+		c.SummaryOnly = true
+	}
+}
+
+// SummaryReport has the flagged and processed counts, and estimated savings, for each check
+type SummaryReport struct {
+	Checks []*TrustedAdvisorCheck
+}
+
+var categoryOrder = []string{"cost_optimizing", "security", "fault_tolerance", "performance", "service_limits"}
+
+var categoryTitles = map[string]string{
+	"cost_optimizing": "Cost Optimization",
+	"security":        "Security",
+	"fault_tolerance": "Fault Tolerance",
+	"performance":     "Performance",
+	"service_limits":  "Service Limits",
+}
+
+func (r *SummaryReport) AsciiReport() string {
+	if len(r.Checks) == 0 {
+		return "Summary: No checks\n"
+	}
+
+	byCategory := map[string][]*TrustedAdvisorCheck{}
+	categories := append([]string{}, categoryOrder...)
+	for _, c := range r.Checks {
+		if _, ok := byCategory[c.Category]; !ok {
+			if _, known := categoryTitles[c.Category]; !known {
+				categories = append(categories, c.Category)
+			}
+		}
+		byCategory[c.Category] = append(byCategory[c.Category], c)
+	}
+
+	o := &strings.Builder{}
+	for _, category := range categories {
+		checks := byCategory[category]
+		if len(checks) == 0 {
+			continue
+		}
+
+		title, ok := categoryTitles[category]
+		if !ok {
+			title = category
+		}
+		o.WriteString(title)
+		o.WriteString("\n")
+
+		headers := []string{"Check", "Status", "Flagged", "Processed"}
+		if category == "cost_optimizing" {
+			headers = append(headers, "Estimated Monthly Savings")
+		}
+
+		var rows [][]string
+		savings := 0
+		for _, c := range checks {
+			row := []string{c.Name, c.Status, strconv.FormatInt(c.Flagged, 10), strconv.FormatInt(c.Processed, 10)}
+			if category == "cost_optimizing" {
+				row = append(row, PrintDollars(c.EstimatedMonthlySavings))
+				savings += c.EstimatedMonthlySavings
+			}
+			rows = append(rows, row)
+		}
+		if category == "cost_optimizing" {
+			rows = append(rows, []string{"Total", "", "", "", PrintDollars(savings)})
+		}
+
+		Table(o, headers, rows)
+		o.WriteString("\n")
+	}
+	return o.String()
+}
+
+// summarizeTrustedAdvisorChecks fetches the summary of every check, in batches.
+// Unlike ListNonOKTrustedAdvisorChecks, checks with an ok status are included.
+// A batch that fails is left out, the others are still returned.
+func summarizeTrustedAdvisorChecks(ctx context.Context, c supportiface.SupportAPI, descriptions []*support.TrustedAdvisorCheckDescription) ([]*TrustedAdvisorCheck, error) {
+	byID := make(map[string]*support.TrustedAdvisorCheckDescription, len(descriptions))
+	for _, ch := range descriptions {
+		byID[aws.StringValue(ch.Id)] = ch
+	}
+
+	var results []*TrustedAdvisorCheck
+	var err error
+	for start := 0; start < len(descriptions); start += summaryBatchSize {
+		end := start + summaryBatchSize
+		if end > len(descriptions) {
+			end = len(descriptions)
+		}
+
+		var ids []*string
+		for _, ch := range descriptions[start:end] {
+			ids = append(ids, ch.Id)
+		}
+
+		o, batchErr := c.DescribeTrustedAdvisorCheckSummariesWithContext(ctx, &support.DescribeTrustedAdvisorCheckSummariesInput{CheckIds: ids})
+		if batchErr != nil {
+			err = errs.Append(err, batchErr)
+			continue
+		}
+
+		for _, sum := range o.Summaries {
+			ch, ok := byID[aws.StringValue(sum.CheckId)]
+			if !ok {
+				continue
+			}
+
+			tac := &TrustedAdvisorCheck{
+				Name:        aws.StringValue(ch.Name),
+				ID:          aws.StringValue(ch.Id),
+				Category:    aws.StringValue(ch.Category),
+				Status:      aws.StringValue(sum.Status),
+				Description: aws.StringValue(ch.Description),
+
+				Check:   ch,
+				Summary: sum,
+			}
+			if sum.ResourcesSummary != nil {
+				tac.Flagged = aws.Int64Value(sum.ResourcesSummary.ResourcesFlagged)
+				tac.Processed = aws.Int64Value(sum.ResourcesSummary.ResourcesProcessed)
+			}
+			if sum.CategorySpecificSummary != nil && sum.CategorySpecificSummary.CostOptimizing != nil {
+				tac.EstimatedMonthlySavings = int(aws.Float64Value(sum.CategorySpecificSummary.CostOptimizing.EstimatedMonthlySavings))
+			}
+			results = append(results, tac)
+		}
+	}
+	return results, err
+}
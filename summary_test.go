@@ -0,0 +1,58 @@
+package chanute_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/support"
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+// flakySummarySupport fails the summaries of the batch with a check
+type flakySummarySupport struct {
+	*chanutetest.Support
+	fail string
+}
+
+func (s *flakySummarySupport) DescribeTrustedAdvisorCheckSummariesWithContext(ctx aws.Context, in *support.DescribeTrustedAdvisorCheckSummariesInput, opts ...request.Option) (*support.DescribeTrustedAdvisorCheckSummariesOutput, error) {
+	for _, id := range aws.StringValueSlice(in.CheckIds) {
+		if id == s.fail {
+			return nil, awserr.New("Throttling", "Rate exceeded", nil)
+		}
+	}
+	return s.Support.DescribeTrustedAdvisorCheckSummariesWithContext(ctx, in, opts...)
+}
+
+func TestSummaryKeepsBatchesThatSucceed(t *testing.T) {
+	f := chanutetest.New()
+	// 120 checks are summarized in 3 batches, of 50, 50 and 20
+	var checks []chanute.Check
+	for i := 0; i < 120; i++ {
+		name := fmt.Sprintf("Check %03d", i)
+		f.Support.AddCheck(fmt.Sprintf("check-%03d", i), name, "security", nil)
+		checks = append(checks, chanute.Check(name))
+	}
+	c := f.Clients()
+	c.Support = &flakySummarySupport{Support: f.Support, fail: "check-075"}
+
+	r, err := chanute.GenerateClientReport(c, chanute.WithSummaryOnly(), chanute.WithChecks(checks...))
+	if err == nil {
+		t.Error("expected the failed batch's error")
+	}
+	if r == nil || r.Summary == nil {
+		t.Fatalf("expected a summary, got %+v", r)
+	}
+	if len(r.Summary.Checks) != 70 {
+		t.Fatalf("expected the 70 checks of the other batches, got %d", len(r.Summary.Checks))
+	}
+	for _, ch := range r.Summary.Checks {
+		var n int
+		if _, scanErr := fmt.Sscanf(ch.ID, "check-%d", &n); scanErr != nil || (n >= 50 && n < 100) {
+			t.Errorf("%s isn't in a batch that succeeded", ch.ID)
+		}
+	}
+}
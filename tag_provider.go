@@ -0,0 +1,204 @@
+package chanute
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi/resourcegroupstaggingapiiface"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// TagProvider looks up the tags of resources of one kind in a region, by the ID or name the reports use.
+// Region is empty for global resources like IAM users.
+// Resources without tags can be left out of the result.
+type TagProvider interface {
+	Tags(ctx context.Context, c *Clients, kind TagKind, region string, ids []*string) (TagMap, error)
+}
+
+// awsTagLookups are the per-service lookups used by AWSTagProvider
+var awsTagLookups = map[TagKind]func(context.Context, *Clients, []*string) (TagMap, error){
+	TagKindEC2Instance:       GetEC2TagsWithContext,
+	TagKindEBSVolume:         GetEBSTagsWithContext,
+	TagKindSecurityGroup:     GetSecurityGroupTagsWithContext,
+	TagKindReservedInstances: GetReservedInstanceTagsWithContext,
+	TagKindRDSInstance:       GetRDSTagsWithContext,
+	TagKindRedshiftCluster: func(ctx context.Context, c *Clients, _ []*string) (TagMap, error) {
+		// every cluster in the region is described, so the names aren't needed
+		return GetRedshiftTagsWithContext(ctx, c)
+	},
+	TagKindLoadBalancer:        GetLBTagsFromNamesWithContext,
+	TagKindClassicLoadBalancer: GetClassicLBTagsWithContext,
+	TagKindAutoScalingGroup:    GetAutoScalingGroupTagsWithContext,
+	TagKindS3Bucket:            GetS3BucketTagsWithContext,
+	TagKindIAMUser:             GetIAMUserTagsWithContext,
+}
+
+// AWSTagProvider looks up tags with the AWS APIs, using clients for the resource's region
+type AWSTagProvider struct {
+	// TaggingAPI looks up tags with one Resource Groups Tagging API call per region, instead of a call per service.
+	// The per-service calls are still used for resources it doesn't support, and when it fails.
+	TaggingAPI bool
+
+	// tagging is each region's GetResources result, by client, so a region is only looked up once per provider
+	mu      sync.Mutex
+	tagging map[resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI]*taggedRegion
+}
+
+type taggedRegion struct {
+	once   sync.Once
+	tagged TaggedResources
+	err    error
+}
+
+func (p *AWSTagProvider) Tags(ctx context.Context, c *Clients, kind TagKind, region string, ids []*string) (TagMap, error) {
+	c = c.InRegion(region)
+	if p.TaggingAPI && c.ResourceGroupsTagging != nil {
+		for _, k := range taggingAPIKinds {
+			if k != kind {
+				continue
+			}
+			if tagged, err := p.taggedResources(ctx, c); err == nil {
+				return tagged.get(kind, ids), nil
+			}
+		}
+	}
+
+	lookup, ok := awsTagLookups[kind]
+	if !ok {
+		return nil, errs.New("no AWS tag lookup for " + string(kind))
+	}
+	return lookup(ctx, c, ids)
+}
+
+func (p *AWSTagProvider) taggedResources(ctx context.Context, c *Clients) (TaggedResources, error) {
+	p.mu.Lock()
+	if p.tagging == nil {
+		p.tagging = map[resourcegroupstaggingapiiface.ResourceGroupsTaggingAPIAPI]*taggedRegion{}
+	}
+	r, ok := p.tagging[c.ResourceGroupsTagging]
+	if !ok {
+		r = &taggedRegion{}
+		p.tagging[c.ResourceGroupsTagging] = r
+	}
+	p.mu.Unlock()
+
+	r.once.Do(func() {
+		r.tagged, r.err = GetTaggedResourcesWithContext(ctx, c)
+	})
+	return r.tagged, r.err
+}
+
+// StaticTagProvider has tags by resource ID or name, such as an export of a CMDB.
+// Names that are reused across regions can be prefixed by their region, "us-west-2/db-name", which takes precedence.
+type StaticTagProvider struct {
+	Resources TagMap
+}
+
+func (p *StaticTagProvider) Tags(_ context.Context, _ *Clients, _ TagKind, region string, ids []*string) (TagMap, error) {
+	tags := TagMap{}
+	for _, id := range ids {
+		name := aws.StringValue(id)
+		if t, ok := p.Resources[region+"/"+name]; ok && region != "" {
+			tags[name] = t
+		} else if t, ok := p.Resources[name]; ok {
+			tags[name] = t
+		}
+	}
+	return tags, nil
+}
+
+// StaticTagProviderFromFile reads tags from a .json or .csv file.
+// JSON is an object of resource IDs to objects of tags.
+// CSV has a header row, the first column is the resource ID and the rest are tags, empty values are left out.
+func StaticTagProviderFromFile(path string) (*StaticTagProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	defer f.Close()
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		return readJSONTags(f)
+	case ".csv":
+		return readCSVTags(f)
+	default:
+		return nil, errs.New("unsupported tag file type " + ext + ", expected .json or .csv")
+	}
+}
+
+func readJSONTags(r io.Reader) (*StaticTagProvider, error) {
+	p := &StaticTagProvider{}
+	if err := json.NewDecoder(r).Decode(&p.Resources); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return p, nil
+}
+
+func readCSVTags(r io.Reader) (*StaticTagProvider, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if len(rows) == 0 {
+		return nil, errs.New("tag file has no header row")
+	}
+
+	header := rows[0]
+	p := &StaticTagProvider{Resources: TagMap{}}
+	for _, row := range rows[1:] {
+		if len(row) == 0 || row[0] == "" {
+			continue
+		}
+		tags := p.Resources[row[0]]
+		if tags == nil {
+			tags = map[string]string{}
+			p.Resources[row[0]] = tags
+		}
+		for i := 1; i < len(row) && i < len(header); i++ {
+			if row[i] != "" {
+				tags[header[i]] = row[i]
+			}
+		}
+	}
+	return p, nil
+}
+
+// ChainTagProviders merges the tags of every provider, earlier providers take precedence when they have the same key.
+// A provider failing doesn't stop the others, its error is returned with the tags that were found.
+func ChainTagProviders(providers ...TagProvider) TagProvider {
+	return chainTagProvider(providers)
+}
+
+type chainTagProvider []TagProvider
+
+func (providers chainTagProvider) Tags(ctx context.Context, c *Clients, kind TagKind, region string, ids []*string) (TagMap, error) {
+	merged := TagMap{}
+	var err error
+	for _, p := range providers {
+		tags, tagErr := p.Tags(ctx, c, kind, region, ids)
+		if tagErr != nil {
+			err = errs.Append(err, tagErr)
+		}
+		for id, resourceTags := range tags {
+			m := merged[id]
+			if m == nil {
+				m = make(map[string]string, len(resourceTags))
+				merged[id] = m
+			}
+			for k, v := range resourceTags {
+				if _, ok := m[k]; !ok {
+					m[k] = v
+				}
+			}
+		}
+	}
+	return merged, err
+}
@@ -0,0 +1,71 @@
+package chanute_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/sheeley/chanute"
+)
+
+// failingTagProvider returns its tags along with an error, like a provider that failed partway through
+type failingTagProvider struct {
+	tags  chanute.TagMap
+	calls int
+}
+
+func (p *failingTagProvider) Tags(context.Context, *chanute.Clients, chanute.TagKind, string, []*string) (chanute.TagMap, error) {
+	p.calls++
+	return p.tags, errors.New("tag source unavailable")
+}
+
+func TestChainTagProviders(t *testing.T) {
+	cmdb := &chanute.StaticTagProvider{Resources: chanute.TagMap{
+		"i-1":           {"team": "cmdb"},
+		"us-west-2/i-2": {"team": "cmdb west"},
+	}}
+	failing := &failingTagProvider{tags: chanute.TagMap{
+		"i-1": {"team": "failing", "owner": "failing"},
+		"i-3": {"owner": "failing"},
+	}}
+	fallback := &chanute.StaticTagProvider{Resources: chanute.TagMap{
+		"i-1": {"team": "fallback", "owner": "fallback", "env": "prod"},
+		"i-2": {"team": "fallback"},
+		"i-3": {"owner": "fallback", "env": "dev"},
+		"i-4": {"env": "test"},
+	}}
+
+	tags, err := chanute.ChainTagProviders(cmdb, failing, fallback).Tags(context.Background(), nil, chanute.TagKindEC2Instance, "us-west-2", aws.StringSlice([]string{"i-1", "i-2", "i-3", "i-4", "i-5"}))
+	if err == nil || !strings.Contains(err.Error(), "tag source unavailable") {
+		t.Errorf("expected the failing provider's error, got %v", err)
+	}
+	if failing.calls != 1 {
+		t.Errorf("expected the failing provider to be called once, got %d", failing.calls)
+	}
+
+	// each key comes from the first provider that has it, the providers after the failing one are still used
+	want := chanute.TagMap{
+		"i-1": {"team": "cmdb", "owner": "failing", "env": "prod"},
+		"i-2": {"team": "cmdb west"},
+		"i-3": {"owner": "failing", "env": "dev"},
+		"i-4": {"env": "test"},
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("got %v, expected %v", tags, want)
+	}
+}
+
+func TestChainTagProvidersDoesntChangeTags(t *testing.T) {
+	first := &chanute.StaticTagProvider{Resources: chanute.TagMap{"i-1": {"team": "data"}}}
+	second := &chanute.StaticTagProvider{Resources: chanute.TagMap{"i-1": {"env": "prod"}}}
+
+	if _, err := chanute.ChainTagProviders(first, second).Tags(context.Background(), nil, chanute.TagKindEC2Instance, "us-east-1", aws.StringSlice([]string{"i-1"})); err != nil {
+		t.Fatal(err)
+	}
+	if got := first.Resources["i-1"]; len(got) != 1 {
+		t.Errorf("the first provider's tags were changed to %v", got)
+	}
+}
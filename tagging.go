@@ -0,0 +1,111 @@
+package chanute
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/richardwilkes/toolbox/errs"
+)
+
+// TagKind is a kind of resource chanute looks up tags for, as "service:resource type" like the Resource Groups Tagging API.
+// Load balancers are split in two, because classic and v2 load balancers can share a name.
+type TagKind string
+
+const (
+	TagKindEC2Instance         TagKind = "ec2:instance"
+	TagKindEBSVolume           TagKind = "ec2:volume"
+	TagKindSecurityGroup       TagKind = "ec2:security-group"
+	TagKindReservedInstances   TagKind = "ec2:reserved-instances"
+	TagKindRDSInstance         TagKind = "rds:db"
+	TagKindRedshiftCluster     TagKind = "redshift:cluster"
+	TagKindLoadBalancer        TagKind = "elasticloadbalancing:loadbalancer"
+	TagKindClassicLoadBalancer TagKind = "elasticloadbalancing:loadbalancer/classic"
+	// Auto Scaling groups, buckets and IAM users aren't looked up in the Resource Groups Tagging API
+	TagKindAutoScalingGroup TagKind = "autoscaling:autoScalingGroup"
+	TagKindS3Bucket         TagKind = "s3:bucket"
+	TagKindIAMUser          TagKind = "iam:user"
+)
+
+// taggingAPIKinds are every kind looked up in one GetResources call per region
+var taggingAPIKinds = []TagKind{
+	TagKindEC2Instance,
+	TagKindEBSVolume,
+	TagKindSecurityGroup,
+	TagKindReservedInstances,
+	TagKindRDSInstance,
+	TagKindRedshiftCluster,
+	TagKindLoadBalancer,
+	TagKindClassicLoadBalancer,
+}
+
+// filter is the resource type filter GetResources expects
+func (k TagKind) filter() string {
+	return strings.SplitN(string(k), "/", 2)[0]
+}
+
+// TaggedResources are tags by resource kind, then by the resource ID or name chanute uses for that kind
+type TaggedResources map[TagKind]TagMap
+
+// GetTaggedResources is GetTaggedResourcesWithContext using a background context
+func GetTaggedResources(c *Clients) (TaggedResources, error) {
+	return GetTaggedResourcesWithContext(context.Background(), c)
+}
+
+// GetTaggedResourcesWithContext looks up the tags of every resource chanute reports on in c's region,
+// with one paginated Resource Groups Tagging API call. Resources that have never been tagged aren't returned.
+func GetTaggedResourcesWithContext(ctx context.Context, c *Clients) (TaggedResources, error) {
+	filters := map[string]bool{}
+	for _, k := range taggingAPIKinds {
+		filters[k.filter()] = true
+	}
+	in := &resourcegroupstaggingapi.GetResourcesInput{}
+	for f := range filters {
+		in.ResourceTypeFilters = append(in.ResourceTypeFilters, aws.String(f))
+	}
+	sort.Slice(in.ResourceTypeFilters, func(i, j int) bool {
+		return aws.StringValue(in.ResourceTypeFilters[i]) < aws.StringValue(in.ResourceTypeFilters[j])
+	})
+
+	tagged := TaggedResources{}
+	var parseErr error
+	for {
+		o, err := c.ResourceGroupsTagging.GetResourcesWithContext(ctx, in)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		for _, r := range o.ResourceTagMappingList {
+			kind, name, err := taggedResourceName(aws.StringValue(r.ResourceARN))
+			if err != nil {
+				parseErr = errs.Append(parseErr, err)
+				continue
+			}
+			if tagged[kind] == nil {
+				tagged[kind] = TagMap{}
+			}
+			tags := make(map[string]string, len(r.Tags))
+			for _, t := range r.Tags {
+				tags[aws.StringValue(t.Key)] = aws.StringValue(t.Value)
+			}
+			tagged[kind][name] = tags
+		}
+		if aws.StringValue(o.PaginationToken) == "" {
+			break
+		}
+		in.PaginationToken = o.PaginationToken
+	}
+	return tagged, parseErr
+}
+
+func (t TaggedResources) get(kind TagKind, ids []*string) TagMap {
+	// resources that aren't returned have never been tagged
+	tags := TagMap{}
+	for _, id := range ids {
+		if resourceTags, ok := t[kind][aws.StringValue(id)]; ok {
+			tags[aws.StringValue(id)] = resourceTags
+		}
+	}
+	return tags
+}
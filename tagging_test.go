@@ -0,0 +1,122 @@
+package chanute_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/sheeley/chanute"
+	"github.com/sheeley/chanute/chanutetest"
+)
+
+// countingTagging counts GetResources calls, and fails them if denied is set
+type countingTagging struct {
+	*chanutetest.ResourceGroupsTagging
+	calls  int
+	denied bool
+}
+
+func (t *countingTagging) GetResourcesWithContext(ctx aws.Context, in *resourcegroupstaggingapi.GetResourcesInput, opts ...request.Option) (*resourcegroupstaggingapi.GetResourcesOutput, error) {
+	t.calls++
+	if t.denied {
+		return nil, awserr.New("AccessDeniedException", "not authorized to perform tag:GetResources", nil)
+	}
+	return t.ResourceGroupsTagging.GetResourcesWithContext(ctx, in, opts...)
+}
+
+func TestGetTaggedResources(t *testing.T) {
+	f := chanutetest.New()
+	f.ResourceGroupsTagging.PageSize = 2
+	for arn, team := range map[string]string{
+		"arn:aws:ec2:us-east-1:123456789012:instance/i-1":                               "ec2",
+		"arn:aws:ec2:us-east-1:123456789012:volume/vol-1":                               "ebs",
+		"arn:aws:rds:us-east-1:123456789012:db:orders":                                  "rds",
+		"arn:aws:redshift:us-east-1:123456789012:cluster:warehouse":                     "redshift",
+		"arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/app-lb/1": "elbv2",
+		"arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/classic-lb":   "elb",
+		"arn:aws:elasticloadbalancing:us-east-1:123456789012:targetgroup/web/1":         "target group",
+		"arn:aws:sqs:us-east-1:123456789012:queue":                                      "sqs",
+	} {
+		f.ResourceGroupsTagging.AddResource(arn, map[string]string{"team": team})
+	}
+
+	tagged, err := chanute.GetTaggedResources(f.Clients())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[chanute.TagKind]map[string]string{}
+	for kind, tags := range tagged {
+		got[kind] = map[string]string{}
+		for id, resourceTags := range tags {
+			got[kind][id] = resourceTags["team"]
+		}
+	}
+	// every page is read, and target groups and other services are filtered out
+	want := map[chanute.TagKind]map[string]string{
+		chanute.TagKindEC2Instance:         {"i-1": "ec2"},
+		chanute.TagKindEBSVolume:           {"vol-1": "ebs"},
+		chanute.TagKindRDSInstance:         {"orders": "rds"},
+		chanute.TagKindRedshiftCluster:     {"warehouse": "redshift"},
+		chanute.TagKindLoadBalancer:        {"app-lb": "elbv2"},
+		chanute.TagKindClassicLoadBalancer: {"classic-lb": "elb"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, expected %v", got, want)
+	}
+}
+
+func TestAWSTagProviderTaggingAPI(t *testing.T) {
+	f := chanutetest.New()
+	f.EC2.AddInstance("i-1", map[string]string{"team": "from ec2"})
+	f.S3.AddBucket("logs", map[string]string{"team": "from s3"})
+	f.ResourceGroupsTagging.AddResource("arn:aws:ec2:us-east-1:123456789012:instance/i-1", map[string]string{"team": "from tagging"})
+	f.ResourceGroupsTagging.AddResource("arn:aws:ec2:us-east-1:123456789012:volume/vol-1", map[string]string{"team": "from tagging"})
+
+	tests := []struct {
+		name   string
+		denied bool
+		kind   chanute.TagKind
+		id     string
+		want   string
+		calls  int
+	}{
+		{name: "tagging API", kind: chanute.TagKindEC2Instance, id: "i-1", want: "from tagging", calls: 1},
+		{name: "per service lookup when the tagging API is denied", denied: true, kind: chanute.TagKindEC2Instance, id: "i-1", want: "from ec2", calls: 1},
+		{name: "per service lookup for kinds the tagging API isn't used for", kind: chanute.TagKindS3Bucket, id: "logs", want: "from s3"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := f.Clients()
+			tagging := &countingTagging{ResourceGroupsTagging: f.ResourceGroupsTagging, denied: test.denied}
+			c.ResourceGroupsTagging = tagging
+			p := &chanute.AWSTagProvider{TaggingAPI: true}
+
+			// the second lookup reuses the region's GetResources result
+			for i := 0; i < 2; i++ {
+				tags, err := p.Tags(context.Background(), c, test.kind, "us-east-1", aws.StringSlice([]string{test.id}))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := tags[test.id]["team"]; got != test.want {
+					t.Errorf("got team %q, expected %q", got, test.want)
+				}
+			}
+			if tagging.calls != test.calls {
+				t.Errorf("expected %d GetResources calls, got %d", test.calls, tagging.calls)
+			}
+		})
+	}
+
+	// a volume in the tagging API isn't looked up with EC2
+	tags, err := (&chanute.AWSTagProvider{TaggingAPI: true}).Tags(context.Background(), f.Clients(), chanute.TagKindEBSVolume, "us-east-1", aws.StringSlice([]string{"vol-1", "vol-2"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags["vol-1"]["team"] != "from tagging" {
+		t.Errorf("expected tags for vol-1 only, got %v", tags)
+	}
+}